@@ -0,0 +1,76 @@
+// Command hitter-replay feeds recorded slack events through the bot handler
+// and prints the slack API calls made for each of them.
+//
+// The events are read one JSON per line from the files or the standard input.
+// Lines of the debug log written with LOG_LEVEL=debug can be replayed as they are.
+//
+//	hitter-replay -members C0123=U0001,U0002,U0003 events.ndjson
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/bot"
+)
+
+// The -members flag, which can be given for each channel
+type membersFlag map[string][]string
+
+func (m membersFlag) String() string {
+	var list []string
+	for channel, users := range m {
+		list = append(list, channel+"="+strings.Join(users, ","))
+	}
+
+	return strings.Join(list, " ")
+}
+
+func (m membersFlag) Set(value string) error {
+	channel, users := value, ""
+	if i := strings.Index(value, "="); i >= 0 {
+		channel, users = value[:i], value[i+1:]
+	}
+	if channel == "" || users == "" {
+		return fmt.Errorf("expected CHANNEL=USER,USER: %s", value)
+	}
+	m[channel] = append(m[channel], strings.Split(users, ",")...)
+
+	return nil
+}
+
+func main() {
+	members := membersFlag{}
+	opts := bot.ReplayOptions{Members: members}
+	flag.BoolVar(&opts.Real, "real", false, "use slack and AWS configured by the environment variables instead of the fakes")
+	flag.BoolVar(&opts.JSON, "json", false, "write each result as a JSON line")
+	flag.Var(members, "members", "members of a channel on the fake slack server as CHANNEL=USER,USER (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		var readers []io.Reader
+		for _, name := range flag.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				log.Fatalln("[ERROR] Failed to open the events: ", err)
+			}
+			defer f.Close()
+			readers = append(readers, f)
+		}
+		input = io.MultiReader(readers...)
+	}
+
+	err := bot.Replay(input, os.Stdout, opts)
+	if err != nil {
+		log.Fatalln("[ERROR] Failed to replay the events: ", err)
+	}
+}
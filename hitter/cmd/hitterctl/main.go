@@ -0,0 +1,104 @@
+// Command hitterctl runs the bot commands from a terminal without slack.
+//
+// The same environment variables as the bot are used, so that the results,
+// such as the expiry of the shortened URLs, are the same as in slack.
+//
+//	hitterctl hit 3 --channel C0123 --ex U0456
+//	hitterctl short https://aws.amazon.com/jp/ --ttl 7
+//	hitterctl link ./file.pdf
+//	hitterctl --json short https://aws.amazon.com/jp/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/bot"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+const usage = `Usage: hitterctl [--json] [--channel CHANNEL] [--user USER] <command> [arguments]
+
+  --json     write the result as JSON
+  --channel  channel whose members are chosen by hit, SLACK_CHANNEL_ID by default
+  --user     user running the command, checked against ADMIN_USER_IDS
+
+Run "hitterctl help" for the commands.
+`
+
+func main() {
+	// The flags of hitterctl can be anywhere, so they are taken out before the command is parsed
+	opts := bot.CommandOptions{}
+	asJSON := false
+	var args []string
+	in := os.Args[1:]
+	for i := 0; i < len(in); i++ {
+		name, val := in[i], ""
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, val, hasValue = name[:j], name[j+1:], true
+		}
+
+		switch name {
+		case "--json", "-json":
+			asJSON = true
+			continue
+		case "--channel", "-channel", "--user", "-user":
+			if !hasValue {
+				if i+1 >= len(in) {
+					fmt.Fprint(os.Stderr, usage)
+					os.Exit(2)
+				}
+				i++
+				val = in[i]
+			}
+			if strings.HasSuffix(name, "channel") {
+				opts.Channel = val
+			} else {
+				opts.User = val
+			}
+			continue
+		case "-h", "--help":
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(0)
+		}
+		args = append(args, in[i])
+	}
+
+	// Loading the environment variables applies LOG_LEVEL or DEBUG_LOG to the log
+	if err := bot.LoadConfig(); err != nil {
+		log.Fatalln("[ERROR] Failed to load the environment variables: ", err)
+	}
+
+	// The log of the bot is only useful for debugging here, so only the warnings are written to stderr unless the level is set
+	_, hasLevel := os.LookupEnv("LOG_LEVEL")
+	_, hasDebug := os.LookupEnv("DEBUG_LOG")
+	if !hasLevel && !hasDebug {
+		logging.SetLevel(logging.LevelWarn)
+	}
+
+	result, err := bot.RunCommand(context.Background(), args, opts)
+	if err != nil {
+		log.Fatalln("[ERROR] Failed to run the command: ", err)
+	}
+
+	if asJSON {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatalln("[ERROR] Failed to write the result: ", err)
+		}
+		fmt.Println(string(b))
+	} else if result.Error != "" {
+		fmt.Fprintln(os.Stderr, result.Text())
+	} else {
+		fmt.Println(result.Text())
+	}
+
+	if result.Error != "" {
+		os.Exit(1)
+	}
+}
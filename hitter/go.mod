@@ -0,0 +1,13 @@
+module github.com/uchimanajet7/hitter/hitter
+
+go 1.15
+
+require (
+	github.com/araddon/dateparse v0.0.0-20200409225146-d820a6159ab1
+	github.com/aws/aws-lambda-go v1.34.1
+	github.com/aws/aws-sdk-go v1.44.298
+	github.com/google/uuid v1.1.5
+	github.com/guregu/dynamo v1.20.0
+	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/slack-go/slack v0.10.1
+)
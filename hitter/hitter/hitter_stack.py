@@ -14,7 +14,14 @@ from aws_cdk import (
 
 # Retrieving Information from Environment Variables
 SLACK_OAUTH_ACCESS_TOKEN = os.environ.get('HITTER_SLACK_OAUTH_ACCESS_TOKEN')
-SLACK_VERIFICATION_TOKEN = os.environ.get('HITTER_SLACK_VERIFICATION_TOKEN')
+SLACK_SIGNING_SECRET = os.environ.get('HITTER_SLACK_SIGNING_SECRET')
+SLACK_PREVIOUS_SIGNING_SECRET = os.environ.get(
+    'HITTER_SLACK_PREVIOUS_SIGNING_SECRET')
+SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY = os.environ.get(
+    'HITTER_SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY')
+SLACK_SIGNATURE_MAX_AGE = os.environ.get('HITTER_SLACK_SIGNATURE_MAX_AGE')
+CALL_TIMEOUT = os.environ.get('HITTER_CALL_TIMEOUT')
+LOG_LEVEL = os.environ.get('HITTER_LOG_LEVEL', 'info')
 ZONE_NAME = os.environ.get('HITTER_ZONE_NAME')
 ZONE_ID = os.environ.get('HITTER_ZONE_ID')
 
@@ -54,6 +61,16 @@ class HitterStack(core.Stack):
                                        removal_policy=core.RemovalPolicy.DESTROY,
                                        )
 
+        # Creating State Table in DynamoDB, such as the history of the hit command
+        state_table = aws_dynamodb.Table(self, "HitterStateTable",
+                                         partition_key=aws_dynamodb.Attribute(
+                                             name="ID",
+                                             type=aws_dynamodb.AttributeType.STRING),
+                                         billing_mode=aws_dynamodb.BillingMode.PAY_PER_REQUEST,
+                                         time_to_live_attribute="TTL",
+                                         removal_policy=core.RemovalPolicy.RETAIN,
+                                         )
+
         # Creating a bucket to be used in a Pre-Signed URL
         bucket = aws_s3.Bucket(self, "HitterS3",
                                removal_policy=core.RemovalPolicy.RETAIN,
@@ -66,20 +83,43 @@ class HitterStack(core.Stack):
         # Setting permission to the salck bot Lambda function
         mutex_table.grant_read_write_data(bot_handler)
         url_table.grant_read_write_data(bot_handler)
+        state_table.grant_read_write_data(bot_handler)
         bucket.grant_put(bot_handler)
         bucket.grant_read(bot_handler)
         bot_handler.add_to_role_policy(aws_iam.PolicyStatement(
             resources=["*"], actions=["comprehend:BatchDetectDominantLanguage", "translate:TranslateText"]))
 
+        # Allow the slack bot Lambda function to invoke itself to run commands asynchronously
+        bot_handler.add_to_role_policy(aws_iam.PolicyStatement(
+            resources=[core.Stack.of(self).format_arn(
+                service="lambda", resource="function", sep=":", resource_name=self.stack_name + "-HitterBot*")],
+            actions=["lambda:InvokeFunction"]))
+
         # Setting environment variables to the salck bot Lambda function
         bot_handler.add_environment(
             'SLACK_OAUTH_ACCESS_TOKEN', SLACK_OAUTH_ACCESS_TOKEN)
         bot_handler.add_environment(
-            'SLACK_VERIFICATION_TOKEN', SLACK_VERIFICATION_TOKEN)
+            'SLACK_SIGNING_SECRET', SLACK_SIGNING_SECRET)
         bot_handler.add_environment('MUTEX_TABLE_NAME', mutex_table.table_name)
         bot_handler.add_environment('URL_TABLE_NAME', url_table.table_name)
+        bot_handler.add_environment('STATE_TABLE_NAME', state_table.table_name)
         bot_handler.add_environment('S3_BUCKET_NAME', bucket.bucket_name)
-        bot_handler.add_environment('DEBUG_LOG', "false")
+        bot_handler.add_environment('LOG_LEVEL', LOG_LEVEL)
+
+        # Only set while rotating the signing secret
+        if SLACK_PREVIOUS_SIGNING_SECRET:
+            bot_handler.add_environment(
+                'SLACK_PREVIOUS_SIGNING_SECRET', SLACK_PREVIOUS_SIGNING_SECRET)
+        if SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY:
+            bot_handler.add_environment(
+                'SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY', SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY)
+        if SLACK_SIGNATURE_MAX_AGE:
+            bot_handler.add_environment(
+                'SLACK_SIGNATURE_MAX_AGE', SLACK_SIGNATURE_MAX_AGE)
+
+        # Only set to change the timeout of each call to slack and AWS
+        if CALL_TIMEOUT:
+            bot_handler.add_environment('CALL_TIMEOUT', CALL_TIMEOUT)
 
         # Creating an API Gateway for a slack bot
         bot_api = aws_apigateway.LambdaRestApi(
@@ -124,7 +164,7 @@ class HitterStack(core.Stack):
         # Setting environment variables to the salck bot Lambda function
         short_url_handler.add_environment(
             'URL_TABLE_NAME', url_table.table_name)
-        short_url_handler.add_environment('DEBUG_LOG', "false")
+        short_url_handler.add_environment('LOG_LEVEL', LOG_LEVEL)
 
         # Creating an API Gateway for a Short URL
         short_url_api = aws_apigateway.LambdaRestApi(
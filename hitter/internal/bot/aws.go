@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/comprehend"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/translate"
+	"github.com/uchimanajet7/hitter/hitter/internal/lazy"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+type awsClient struct {
+	session  *session.Session
+	dynamoDB *storage.DynamoDB
+
+	// Only created when a command needs them
+	s3Once           sync.Once
+	s3               *storage.S3
+	comprehendOnce   sync.Once
+	comprehendClient *comprehend.Comprehend
+	translateOnce    sync.Once
+	translateClient  *translate.Translate
+	lambdaOnce       sync.Once
+	lambdaClient     *awslambda.Lambda
+}
+
+// The client is kept while the Lambda container is warm
+var sharedAwsClient lazy.Value
+
+func getAwsClient() (*awsClient, error) {
+	v, err := sharedAwsClient.Get(func() (interface{}, error) {
+		return newAwsClient()
+	})
+
+	return v.(*awsClient), err
+}
+
+func newAwsClient() (*awsClient, error) {
+	ac := &awsClient{}
+	sess, err := session.NewSession()
+	if err != nil {
+		log.Println("[ERROR] Failed to create the aws session: ", err)
+		return nil, err
+	}
+	ac.session = sess
+
+	// Connect to DynamoDB Local if an endpoint is specified
+	endpoint := ""
+	if envconf != nil {
+		endpoint = envconf.DynamoDBEndpoint
+	}
+	ac.dynamoDB = storage.NewDynamoDB(ac.session, endpoint)
+
+	return ac, nil
+}
+
+func (c *awsClient) getS3() *storage.S3 {
+	c.s3Once.Do(func() {
+		c.s3 = storage.NewS3(c.session)
+	})
+
+	return c.s3
+}
+
+func (c *awsClient) getComprehend() *comprehend.Comprehend {
+	c.comprehendOnce.Do(func() {
+		c.comprehendClient = comprehend.New(c.session)
+	})
+
+	return c.comprehendClient
+}
+
+func (c *awsClient) getTranslate() *translate.Translate {
+	c.translateOnce.Do(func() {
+		c.translateClient = translate.New(c.session)
+	})
+
+	return c.translateClient
+}
+
+func (c *awsClient) getLambda() *awslambda.Lambda {
+	c.lambdaOnce.Do(func() {
+		c.lambdaClient = awslambda.New(c.session)
+	})
+
+	return c.lambdaClient
+}
+
+// The tables and the bucket are shared with the redirect function in internal/storage
+
+func (c *awsClient) putURLItem(ctx context.Context, tableName string, id string, urlStr string, days int) (int64, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.PutURLItem(ctx, tableName, id, urlStr, days)
+}
+
+func (c *awsClient) getURLItem(ctx context.Context, tableName string, id string) (*storage.URLItem, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.GetURLItem(ctx, tableName, id)
+}
+
+func (c *awsClient) acquireMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, bool, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.AcquireMutexItem(ctx, tableName, id)
+}
+
+func (c *awsClient) restartMutexItem(ctx context.Context, tableName string, id string) (bool, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.RestartMutexItem(ctx, tableName, id)
+}
+
+func (c *awsClient) completeMutexItem(ctx context.Context, tableName string, id string, status string, result string) error {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.CompleteMutexItem(ctx, tableName, id, status, result)
+}
+
+func (c *awsClient) getMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.GetMutexItem(ctx, tableName, id)
+}
+
+func (c *awsClient) getStateItem(ctx context.Context, tableName string, id string) (*storage.StateItem, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.GetStateItem(ctx, tableName, id)
+}
+
+func (c *awsClient) putStateItem(ctx context.Context, tableName string, item *storage.StateItem, days int) error {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.dynamoDB.PutStateItem(ctx, tableName, item, days)
+}
+
+func (c *awsClient) uploadAndPreSignedURL(ctx context.Context, bucket string, key string, body []byte, min int) (*storage.S3Item, error) {
+	// Large files take long to upload, so only the deadline of the command applies
+	return c.getS3().UploadAndPreSignedURL(ctx, bucket, key, body, min)
+}
+
+func (c *awsClient) invokeAsync(ctx context.Context, functionName string, payload []byte) error {
+	input := &awslambda.InvokeInput{
+		FunctionName:   aws.String(functionName),
+		InvocationType: aws.String(awslambda.InvocationTypeEvent),
+		Payload:        payload,
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("input: %+v\n", input)
+
+	// The response is returned as soon as the invocation is queued
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	_, err := c.getLambda().InvokeWithContext(ctx, input)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to invoke the lambda function: ", err)
+	}
+
+	return err
+}
+
+func (c *awsClient) detectLanguageCode(ctx context.Context, text string) (string, error) {
+	input := &comprehend.BatchDetectDominantLanguageInput{}
+	input.SetTextList([]*string{&text})
+
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	output, err := c.getComprehend().BatchDetectDominantLanguageWithContext(ctx, input)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to aws comprehend detect language: ", err)
+		return "", err
+	}
+
+	code := ""
+	for _, i := range output.ResultList {
+		for _, j := range i.Languages {
+			if *j.LanguageCode != "" {
+				code = *j.LanguageCode
+			}
+			break
+		}
+	}
+
+	return code, nil
+}
+
+func (c *awsClient) translate(ctx context.Context, text string, source string, target string) (string, error) {
+	input := &translate.TextInput{}
+	input.SetSourceLanguageCode(source)
+	input.SetTargetLanguageCode(target)
+	input.SetText(text)
+
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	output, err := c.getTranslate().TextWithContext(ctx, input)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to aws translate translation message: ", err)
+		return "", err
+	}
+
+	return *output.TranslatedText, nil
+}
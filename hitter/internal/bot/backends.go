@@ -0,0 +1,48 @@
+package bot
+
+import "github.com/uchimanajet7/hitter/hitter/internal/lazy"
+
+// Creates the environment and the clients for each request.
+// They are replaced to run the handler against other backends, such as in Replay.
+type backendFactory struct {
+	env   func() (*envConfig, error)
+	slack func(env *envConfig) *slackClient
+	aws   func(env *envConfig) (awsServices, error)
+}
+
+// By default, the environment and the clients are created once and kept while the Lambda container is warm
+var backends = &backendFactory{
+	env: getEnvConfig,
+	slack: func(env *envConfig) *slackClient {
+		api := getSlackAPI(env)
+		return newSlackClientWith(api, api)
+	},
+	aws: func(env *envConfig) (awsServices, error) {
+		c, err := getAwsClient()
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	},
+}
+
+var sharedEnv lazy.Value
+
+func getEnvConfig() (*envConfig, error) {
+	v, err := sharedEnv.Get(func() (interface{}, error) {
+		return loadEnvConfig()
+	})
+
+	return v.(*envConfig), err
+}
+
+var sharedSlackAPI lazy.Value
+
+// The slack client is safe for concurrent use, but slackClient keeps the result of each command
+func getSlackAPI(env *envConfig) *slackAPI {
+	v, _ := sharedSlackAPI.Get(func() (interface{}, error) {
+		return newSlackAPI(env.SlackOAuthAccessToken, env.SlackAPIURL), nil
+	})
+
+	return v.(*slackAPI)
+}
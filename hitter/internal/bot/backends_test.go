@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/lazy"
+	"github.com/uchimanajet7/hitter/hitter/internal/slacktest"
+)
+
+// Sets the environment variables of the bot for the fake slack server
+func setupBackendsTest(tb testing.TB, server *slacktest.Server) {
+	tb.Helper()
+
+	vars := map[string]string{
+		"URL_TABLE_NAME":           "HitterURLTable",
+		"MUTEX_TABLE_NAME":         "HitterMutexTable",
+		"S3_BUCKET_NAME":           "hitter-bucket",
+		"API_BASE_URL":             "https://short.example.com/v1/",
+		"SLACK_OAUTH_ACCESS_TOKEN": "xoxb-test",
+		"SLACK_API_URL":            server.APIURL(),
+		"AWS_REGION":               "ap-northeast-1",
+	}
+	for k, v := range vars {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		tb.Cleanup(func() {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+
+	resetSharedBackends()
+	tb.Cleanup(resetSharedBackends)
+}
+
+func resetSharedBackends() {
+	sharedEnv = lazy.Value{}
+	sharedSlackAPI = lazy.Value{}
+	sharedAwsClient = lazy.Value{}
+}
+
+func TestSharedBackends(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	setupBackendsTest(t, server)
+
+	env, err := backends.env()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := backends.env(); again != env {
+		t.Error("the environment is loaded again")
+	}
+
+	// Each request has its own slackClient over the same slack client
+	sc1, sc2 := backends.slack(env), backends.slack(env)
+	if sc1 == sc2 || sc1.chat != sc2.chat {
+		t.Error("the slack client is not shared")
+	}
+
+	aws1, err := backends.aws(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aws2, _ := backends.aws(env)
+	if aws1 != aws2 {
+		t.Error("the aws client is not shared")
+	}
+
+	// The clients for S3, Comprehend, Translate and Lambda are created on first use
+	c := aws1.(*awsClient)
+	if c.s3 != nil || c.comprehendClient != nil || c.translateClient != nil || c.lambdaClient != nil {
+		t.Error("the service clients are created in advance")
+	}
+	if c.getS3() != c.getS3() {
+		t.Error("the S3 client is created again")
+	}
+}
+
+// The clients created for each request, as before they were shared
+var perRequestBackends = &backendFactory{
+	env: loadEnvConfig,
+	slack: func(env *envConfig) *slackClient {
+		return newSlackClient(env.SlackOAuthAccessToken, env.SlackAPIURL)
+	},
+	aws: func(env *envConfig) (awsServices, error) {
+		c, err := newAwsClient()
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	},
+}
+
+// The help command does not use AWS, so the time is dominated by the setup and the slack API
+func BenchmarkHelpCommand(b *testing.B) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	setupBackendsTest(b, server)
+
+	form := url.Values{}
+	form.Set("channel_id", "C0123")
+	form.Set("user_id", "U0001")
+	form.Set("command", "/hitter")
+	form.Set("text", "help")
+	form.Set("response_url", server.ResponseURL("T0001"))
+	job := &commandJob{Kind: slashCommandJob, Body: form.Encode()}
+
+	run := func(b *testing.B, factory *backendFactory) {
+		saved := *backends
+		defer func() { *backends = saved }()
+		*backends = *factory
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := runJob(context.Background(), job); err != nil {
+				b.Fatal(err)
+			}
+			server.Reset()
+		}
+	}
+
+	b.Run("per_request", func(b *testing.B) {
+		run(b, perRequestBackends)
+	})
+	b.Run("shared", func(b *testing.B) {
+		resetSharedBackends()
+		run(b, backends)
+	})
+}
@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// CommandOptions configures RunCommand.
+type CommandOptions struct {
+	// The channel whose members are chosen by hit, SLACK_CHANNEL_ID by default
+	Channel string
+	// The user running the command, used for the permissions
+	User string
+}
+
+// LoadConfig loads the environment variables of the bot, which also applies LOG_LEVEL or DEBUG_LOG to the log.
+// RunCommand uses the same environment, so the log level can be changed in between.
+func LoadConfig() error {
+	_, err := backends.env()
+
+	return err
+}
+
+// RunCommand runs a bot command from the command line arguments, such as "hit 3 --ex U0123".
+// The same environment variables as the bot are used, and nothing is posted to slack.
+// Local files given as arguments are used as the attachments of the commands that take them, such as link.
+func RunCommand(ctx context.Context, args []string, opts CommandOptions) (*CommandResult, error) {
+	if len(args) == 0 {
+		args = []string{"help"}
+	}
+	spec, ok := registry.lookup(args[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown command: %s", args[0])
+	}
+
+	env, err := backends.env()
+	if err != nil {
+		return nil, err
+	}
+	aws, err := backends.aws(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Members are still read from slack, but the results are only kept in the client
+	sc := backends.slack(env)
+	sc.chat = &localChat{}
+
+	c := &commandParameter{}
+	c.channel = opts.Channel
+	if c.channel == "" {
+		c.channel = env.SlackChannelID
+	}
+	c.from = opts.User
+	c.command = args[0]
+	c.files = make(map[string]string)
+	c.body = " " + commandText(spec, args[1:], c.files)
+	c.text = c.command + c.body
+
+	// Output debug log
+	logger(ctx).Debugf("cmdParam: %+v\n", c)
+
+	if err := c.runCommand(ctx, sc, aws); err != nil {
+		return nil, err
+	}
+	if sc.result == nil {
+		return nil, errors.New("the command did not return a result")
+	}
+
+	return sc.result, nil
+}
+
+// Convert the arguments into the text of a mention, and take the local files as attachments if the command takes them.
+// Each argument is escaped and quoted as needed, so that it is parsed back into the same token as in slack.
+func commandText(spec *commandSpec, args []string, files map[string]string) string {
+	var words []string
+	// The option waiting for its value
+	var pending *optionSpec
+	optionsEnded := false
+	positional := 0
+	for i, arg := range args {
+		// The argument at the position, if any
+		var a *argumentSpec
+		if positional < len(spec.arguments) {
+			a = spec.arguments[positional]
+		}
+
+		switch {
+		case pending != nil:
+			words = append(words, valueText(pending.kind, arg))
+			pending = nil
+		case a != nil && a.rest:
+			// The rest of the text is taken as is, so it is only escaped
+			for _, r := range args[i:] {
+				if a.kind == userValue {
+					r = slackMention(r)
+				} else {
+					r = escapeSlackText(r)
+				}
+				words = append(words, r)
+			}
+			return strings.Join(words, " ")
+		case !optionsEnded && arg == "--":
+			words = append(words, arg)
+			optionsEnded = true
+		case !optionsEnded && strings.HasPrefix(arg, "--"):
+			name := arg
+			if j := strings.Index(arg, "="); j >= 0 {
+				name = arg[:j]
+			}
+			o, ok := spec.lookupOption(name)
+			switch {
+			case !ok:
+				// Unknown options are reported by the command
+				arg = quoteArgument(arg)
+			case name != arg:
+				arg = name + "=" + valueText(o.kind, arg[len(name)+1:])
+			case o.kind != boolValue:
+				pending = o
+			}
+			words = append(words, arg)
+		case spec.attachments && isLocalFile(arg):
+			files[arg] = filepath.Base(arg)
+		case a != nil:
+			words = append(words, valueText(a.kind, arg))
+			positional++
+		default:
+			// Too many arguments are reported by the command
+			words = append(words, quoteArgument(arg))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// The text of the value as it is written in slack
+func valueText(kind valueKind, arg string) string {
+	switch {
+	case kind == userValue:
+		return slackMention(arg)
+	case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
+		return "<" + escapeSlackText(arg) + ">"
+	default:
+		return quoteArgument(arg)
+	}
+}
+
+// Mentions can be given as "U0123", "@U0123" or "<@U0123>"
+func slackMention(id string) string {
+	if strings.HasPrefix(id, "<") {
+		return id
+	}
+
+	return "<@" + strings.TrimPrefix(id, "@") + ">"
+}
+
+// Escape the argument as slack does, and enclose it in double quotes if it contains spaces, quotes or backslashes
+func quoteArgument(arg string) string {
+	escaped := escapeSlackText(arg)
+	needsQuotes := arg == "" || strings.ContainsRune(arg, '\\') || strings.IndexFunc(arg, func(r rune) bool {
+		_, ok := quotePairs[r]
+		return ok || isSpace(r)
+	}) >= 0
+	if !needsQuotes {
+		return escaped
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(escaped) + `"`
+}
+
+func isLocalFile(name string) bool {
+	info, err := os.Stat(name)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Files are read from the local disk, and messages are not posted anywhere
+type localChat struct{}
+
+func (c *localChat) postMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	return channel, "", nil
+}
+
+func (c *localChat) openConversation(ctx context.Context, users ...string) (string, error) {
+	return "", errors.New("Direct messages cannot be opened from a terminal")
+}
+
+func (c *localChat) uploadFile(ctx context.Context, channel string, body []byte, filename string, comment string, ts string) error {
+	return nil
+}
+
+func (c *localChat) downloadFile(ctx context.Context, name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
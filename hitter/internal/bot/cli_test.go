@@ -0,0 +1,239 @@
+package bot
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func setupCLITest(t *testing.T) *memoryAWS {
+	t.Helper()
+
+	server, _, aws := setupCommandTest(t)
+	envconf.SlackChannelID = "C0123"
+
+	saved := *backends
+	t.Cleanup(func() { *backends = saved })
+	backends.env = func() (*envConfig, error) {
+		return envconf, nil
+	}
+	backends.slack = func(env *envConfig) *slackClient {
+		return newSlackClient("xoxb-test", server.APIURL())
+	}
+	backends.aws = func(env *envConfig) (awsServices, error) {
+		return aws, nil
+	}
+
+	return aws
+}
+
+func TestRunCommandHit(t *testing.T) {
+	setupCLITest(t)
+
+	result, err := RunCommand(context.Background(), []string{"hit", "2", "--ex", "U0002"}, CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := result.Result.(*hitResult).Users
+	sort.Strings(users)
+	if result.Error != "" || !reflect.DeepEqual(users, []string{"U0001", "U0003"}) {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunCommandShort(t *testing.T) {
+	aws := setupCLITest(t)
+
+	result, err := RunCommand(context.Background(), []string{"short", "https://aws.amazon.com/jp/?a=1&b=2", "--ttl=7"}, CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	short := result.Result.(*shortResult)
+	if !strings.HasPrefix(short.URL, "https://short.example.com/v1/") {
+		t.Errorf("url = %s", short.URL)
+	}
+	id := strings.TrimPrefix(short.URL, "https://short.example.com/v1/")
+	item, _ := aws.getURLItem(context.Background(), "HitterURLTable", id)
+	if item == nil || item.URL != "https://aws.amazon.com/jp/?a=1&b=2" {
+		t.Errorf("item = %+v", item)
+	}
+	if !strings.Contains(result.Text(), short.URL+"\t") {
+		t.Errorf("text = %q", result.Text())
+	}
+}
+
+func TestRunCommandLink(t *testing.T) {
+	aws := setupCLITest(t)
+
+	name := filepath.Join(t.TempDir(), "report.pdf")
+	if err := ioutil.WriteFile(name, []byte("%PDF"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RunCommand(context.Background(), []string{"link", "30", name}, CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := result.Result.([]*linkResult)
+	if len(links) != 1 || links[0].File != "report.pdf" || links[0].URL == "" {
+		t.Errorf("result = %+v", links)
+	}
+	if len(aws.objects) != 1 {
+		t.Errorf("objects = %+v", aws.objects)
+	}
+}
+
+func TestRunCommandError(t *testing.T) {
+	setupCLITest(t)
+
+	result, err := RunCommand(context.Background(), []string{"hit", "5"}, CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != "There are too many choices: 5/3" {
+		t.Errorf("error = %q", result.Error)
+	}
+
+	if _, err := RunCommand(context.Background(), []string{"unknown"}, CommandOptions{}); err == nil {
+		t.Error("unknown command should fail")
+	}
+}
+
+func TestCommandText(t *testing.T) {
+	rotation, _ := registry.lookup("rotation")
+
+	tests := []struct {
+		name          string
+		spec          *commandSpec
+		args          []string
+		wantText      string
+		wantArguments map[string]string
+		wantOptions   map[string][]string
+	}{
+		{
+			name:          "mentions",
+			spec:          bindTestSpec,
+			args:          []string{"2", "--ex", "U0001", "--ex=@W017HPXHDF0"},
+			wantText:      "2 --ex <@U0001> --ex=<@W017HPXHDF0>",
+			wantArguments: map[string]string{"number": "2"},
+			wantOptions:   map[string][]string{"--ex": {"U0001", "W017HPXHDF0"}, "--window": {"10"}},
+		},
+		{
+			name:          "spaces and quotes",
+			spec:          bindTestSpec,
+			args:          []string{"--items", `code "review"`, "2", "it's a\\b"},
+			wantText:      `--items "code \"review\"" 2 "it's a\\b"`,
+			wantArguments: map[string]string{"number": "2", "name": `it's a\b`},
+			wantOptions:   map[string][]string{"--items": {`code "review"`}, "--window": {"10"}},
+		},
+		{
+			name:          "option value with spaces",
+			spec:          bindTestSpec,
+			args:          []string{"--items=a b", "--fair"},
+			wantText:      `--items="a b" --fair`,
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--items": {"a b"}, "--fair": {"true"}, "--window": {"10"}},
+		},
+		{
+			name:          "slack markup",
+			spec:          bindTestSpec,
+			args:          []string{"3", "a&b<c>", "x > y & z"},
+			wantText:      `3 a&amp;b&lt;c&gt; x &gt; y &amp; z`,
+			wantArguments: map[string]string{"number": "3", "name": "a&b<c>", "text": "x > y & z"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "empty and smart quotes",
+			spec:          bindTestSpec,
+			args:          []string{"4", "", "“a”"},
+			wantText:      `4 "" “a”`,
+			wantArguments: map[string]string{"number": "4", "name": "", "text": "“a”"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "url",
+			spec:          bindTestSpec,
+			args:          []string{"--url", "https://example.com/?a=1&b=<2>"},
+			wantText:      "--url <https://example.com/?a=1&amp;b=&lt;2&gt;>",
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--url": {"https://example.com/?a=1&b=<2>"}, "--window": {"10"}},
+		},
+		{
+			name:          "end of options",
+			spec:          bindTestSpec,
+			args:          []string{"5", "--", "--fair"},
+			wantText:      "5 -- --fair",
+			wantArguments: map[string]string{"number": "5", "name": "--fair"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "positional mentions",
+			spec:          rotation,
+			args:          []string{"reorder", "retro", "U0002", "@U0001"},
+			wantText:      "reorder retro <@U0002> <@U0001>",
+			wantArguments: map[string]string{"action": "reorder", "name": "retro", "members": "<@U0002> <@U0001>"},
+			wantOptions:   map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := commandText(tt.spec, tt.args, map[string]string{})
+			if text != tt.wantText {
+				t.Errorf("commandText() = %s, want %s", text, tt.wantText)
+			}
+
+			// The text is parsed back into the same values
+			c := &commandParameter{command: tt.spec.name, body: " " + text}
+			if err := tt.spec.bind(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.arguments, tt.wantArguments) {
+				t.Errorf("arguments = %q, want %q", c.arguments, tt.wantArguments)
+			}
+			if !reflect.DeepEqual(c.options, tt.wantOptions) {
+				t.Errorf("options = %q, want %q", c.options, tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestRunCommandRotationReorder(t *testing.T) {
+	setupCLITest(t)
+	envconf.StateTableName = "HitterStateTable"
+
+	result, err := RunCommand(context.Background(), []string{"rotation", "reorder", "retro", "U0003", "@U0001"}, CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Error != "" {
+		t.Fatalf("error = %q", result.Error)
+	}
+	if got := result.Result.(*rotationResult).Order; len(got) != 3 || !reflect.DeepEqual(got[:2], []string{"U0003", "U0001"}) {
+		t.Errorf("order = %v", got)
+	}
+}
+
+func TestCommandTextFiles(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "standup")
+	if err := ioutil.WriteFile(name, []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link, _ := registry.lookup("link")
+	rotation, _ := registry.lookup("rotation")
+
+	files := map[string]string{}
+	if text := commandText(link, []string{"30", name}, files); text != "30" || files[name] != "standup" {
+		t.Errorf("link: text = %q, files = %v", text, files)
+	}
+
+	// The other commands take the name of an existing file as a word
+	files = map[string]string{}
+	if text := commandText(rotation, []string{"show", name}, files); text != "show "+name || len(files) != 0 {
+		t.Errorf("rotation: text = %q, files = %v", text, files)
+	}
+}
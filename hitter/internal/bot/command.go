@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+type commandParameter struct {
+	team    string
+	channel string
+	eventTs string
+	text    string
+	to      string
+	from    string
+	command string
+	// Raw text following the command
+	body string
+	// Only for slash commands, the result is returned to the response URL
+	responseURL  string
+	responseType string
+	arguments    map[string]string
+	files        map[string]string
+	options      map[string][]string
+	// Names of the arguments and options that were not given and took the default value
+	defaulted map[string]bool
+}
+
+func parseCommand(ctx context.Context, se *slackEvent) *commandParameter {
+	cmdParam := &commandParameter{}
+	cmdParam.team = se.TeamID
+	cmdParam.channel = se.Event.Channel
+	cmdParam.eventTs = se.Event.EventTs
+	cmdParam.text = se.Event.Text
+	cmdParam.from = se.Event.User
+	cmdParam.arguments = make(map[string]string)
+	cmdParam.options = make(map[string][]string)
+	cmdParam.files = make(map[string]string)
+
+	// If there are attachments, get the download URL.
+	for _, f := range se.Event.Files {
+		cmdParam.files[f.URLPrivateDownload] = f.Name
+	}
+
+	// Parse Text
+	// <@W017HPXHDF0> hit 3 --ex <@W018217962V> --ex <@W017HPXHDF0> --ex <@W018217962V>
+	// "Mention to bot" "command" "argument" "options"
+	// The arguments and options are bound later according to the command declaration.
+	// If the command cannot be executed because the parsing fails, the input string is posted to Slack as an error message.
+	// The text is built from the rich_text blocks if there are any.
+	to, rest := splitHead(se.commandText(ctx))
+	_, cmdParam.to = parseSlackMarkup(to)
+	cmdParam.command, cmdParam.body = splitHead(rest)
+
+	// Output debug log
+	logger(ctx).Debugf("cmdParam: %+v\n", cmdParam)
+
+	return cmdParam
+}
+
+func (c *commandParameter) runCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Show the help if only the bot is mentioned
+	if c.command == "" {
+		c.command = "help"
+	}
+
+	// Determine which commands are entered and execute them individually.
+	spec, ok := registry.lookup(c.command)
+	if !ok {
+		logger(ctx).Println("[COMMAND] The target command was not available:", c.command)
+		return sc.notifyHelpSuccess(ctx, c, createUnknownCommandHelp(ctx, c.command))
+	}
+
+	ctx = logging.NewContext(ctx, logging.Fields{"command": spec.name})
+	logger(ctx).Printf("[COMMAND] Run %s command\n", spec.name)
+
+	// The command is stopped before the deadline, leaving time to report it
+	cmdCtx, cancel := commandContext(ctx)
+	defer cancel()
+
+	// Validate the input according to the command declaration
+	err := spec.checkPermissions(ctx, c)
+	if err == nil {
+		err = spec.bind(ctx, c)
+	}
+	c.command = spec.name
+	if err == nil {
+		// Only show the result to the user who ran the command
+		if c.options["--private"] != nil && c.options["--private"][0] == "true" {
+			c.responseType = slack.ResponseTypeEphemeral
+		}
+		err = spec.handler(c, cmdCtx, sc, aws)
+	}
+
+	if err != nil {
+		message := fmt.Sprintf("Command execution failed. *[%s]*", err)
+		// Point at the offending token in the input
+		if te, ok := err.(*tokenError); ok {
+			message = message + "\n```" + te.pointer() + "```"
+		}
+		// The error of the aborted call is not helpful, so tell the user that it ran out of time
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			logger(ctx).Println("[ERROR] The command timed out: ", err)
+			err = errCommandTimedOut
+			message = fmt.Sprintf("Command execution timed out. *[%s]*", err)
+		}
+		sc.result = &CommandResult{Command: c.command, Error: err.Error()}
+		err = sc.notifyError(ctx, c, message)
+	}
+
+	return err
+}
+
+// Make the error position relative to the text starting with the command name
+func (c *commandParameter) withCommandName(err error) error {
+	if te, ok := err.(*tokenError); ok {
+		te.input = c.command + te.input
+		te.pos += len(c.command)
+	}
+
+	return err
+}
+
+func (c *commandParameter) isEphemeral() bool {
+	return c.responseType == slack.ResponseTypeEphemeral
+}
+
+func (c *commandParameter) intArgument(name string) int {
+	// The value has already been validated when binding
+	num, _ := strconv.Atoi(c.arguments[name])
+
+	return num
+}
+
+func (c *commandParameter) intOption(name string) int {
+	// The value has already been validated when binding
+	val, ok := c.options[name]
+	if !ok {
+		return 0
+	}
+	num, _ := strconv.Atoi(val[0])
+
+	return num
+}
+
+func (c *commandParameter) boolOption(name string) bool {
+	val, ok := c.options[name]
+
+	return ok && val[0] == "true"
+}
+
+// Fields added to the log while the command runs
+func (c *commandParameter) logFields() logging.Fields {
+	return logging.Fields{"team": c.team, "channel": c.channel, "user": c.from, "command": c.command}
+}
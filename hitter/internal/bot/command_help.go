@@ -0,0 +1,34 @@
+package bot
+
+import "context"
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "help",
+		description: "Displays help for the command",
+		arguments: []*argumentSpec{
+			{name: "command", kind: textValue, description: "Command to show the details"},
+		},
+		examples: []string{
+			"help",
+			"help hit",
+		},
+		handler: (*commandParameter).runHelpCommand,
+	})
+}
+
+func (c *commandParameter) runHelpCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	name := c.arguments["command"]
+
+	// Without a command, show the index of all commands
+	if name == "" {
+		return sc.notifyHelpSuccess(ctx, c, createHelpIndex())
+	}
+
+	spec, ok := registry.lookup(name)
+	if !ok {
+		return sc.notifyHelpSuccess(ctx, c, createUnknownCommandHelp(ctx, name))
+	}
+
+	return sc.notifyHelpSuccess(ctx, c, createCommandHelp(spec))
+}
@@ -0,0 +1,234 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "hit",
+		description: "Randomly select from the members in the channel",
+		notes: []string{
+			"It is an error to select more members than the channel has",
+			"With --fair, members who have not been selected for a while are more likely to be selected",
+			"With --rotate, members are selected in turn, see the rotation command",
+			"With --teams or --size, all the members are split into teams as with the teams command, so the number cannot be given",
+		},
+		examples: []string{
+			"hit 2",
+			"hit 3 --ex @userA --ex @userB",
+			"hit 1 --fair --window 5",
+			"hit 1 --rotate standup",
+			"hit --teams 4",
+		},
+		arguments: []*argumentSpec{
+			{name: "number", kind: intValue, defaultValue: "1", min: 1, description: "Number of selections"},
+		},
+		options: append([]*optionSpec{
+			{name: "--ex", kind: userValue, repeatable: true, description: "Member to be excluded"},
+			{name: "--fair", kind: boolValue, description: "Weight the members by how long since they were last selected"},
+			{name: "--window", kind: intValue, defaultValue: "10", min: 1, max: maxHitHistory, description: "Number of past selections taken into account by --fair"},
+			{name: "--rotate", kind: textValue, description: "Name of the rotation to select the next members from"},
+			{name: "--teams", kind: intValue, min: 1, description: "Split the members into this number of teams"},
+		}, teamOptions...),
+		handler: (*commandParameter).runHitCommand,
+	})
+}
+
+// Number of past selections kept for each channel
+const maxHitHistory = 100
+
+// The past selections of hit in a channel, the newest last
+type hitHistory struct {
+	Rounds []*hitRound `json:"rounds"`
+}
+
+type hitRound struct {
+	Time  int64    `json:"time"`
+	Users []string `json:"users"`
+}
+
+func (c *commandParameter) runHitCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Get the value of a command option
+	val, _ := c.options["--ex"]
+	fair := c.boolOption("--fair")
+	rotation, rotate := c.options["--rotate"]
+
+	if fair && rotate {
+		text := "The --fair and --rotate options cannot be used together"
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	// Split all the members into teams instead of selecting some of them
+	if teams, size := c.intOption("--teams"), c.intOption("--size"); teams > 0 || size > 0 {
+		if fair || rotate {
+			text := "The --teams and --size options cannot be used with --fair or --rotate"
+			logger(ctx).Println("[ERROR] " + text)
+			return errors.New(text)
+		}
+		if !c.defaulted["number"] {
+			text := "The number of selections cannot be used with --teams or --size"
+			logger(ctx).Println("[ERROR] " + text)
+			return errors.New(text)
+		}
+		return c.splitTeams(ctx, sc, teams)
+	}
+	if c.options["--apart"] != nil || c.options["--together"] != nil {
+		text := "The --apart and --together options can only be used with --teams or --size"
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+	if rotate {
+		if err := checkRotationName(rotation[0]); err != nil {
+			return err
+		}
+	}
+
+	// The history is needed to weight the members, and the order to rotate them
+	if (fair || rotate) && !stateEnabled() {
+		logger(ctx).Println("[ERROR] " + errStateNotConfigured.Error())
+		return errStateNotConfigured
+	}
+
+	// Get the target users, the excluded members are kept in the rotation
+	members, err := sc.getTargetUsers(ctx, c.channel, nil)
+	if err != nil {
+		return err
+	}
+	users := excludeUsers(members, val)
+
+	// Minimum number of draws is 1
+	num := c.intArgument("number")
+
+	// There are more choices than options.
+	if len(users) < num {
+		text := fmt.Sprintf("There are too many choices: %d/%d", num, len(users))
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	// Take the next members of the rotation, even if there is a tie
+	if rotate {
+		results, err := advanceRotation(ctx, aws, c.channel, rotation[0], members, users, num)
+		if err != nil {
+			return err
+		}
+		c.recordHitHistory(ctx, aws, results)
+
+		// Notify your slack of the results
+		return sc.notifyHitSuccess(ctx, c, results)
+	}
+
+	// If there is a tie, return as is.
+	if len(users) == num {
+		logger(ctx).Println("[SUCCESS] It worked, as there were an equal number of options.")
+		c.recordHitHistory(ctx, aws, users)
+		return sc.notifyHitSuccess(ctx, c, users)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	var results []string
+	if fair {
+		history := &hitHistory{}
+		if _, err := loadState(ctx, aws, stateKey("hit", c.channel), history); err != nil {
+			return err
+		}
+		weights := fairWeights(users, history, c.intOption("--window"))
+
+		// Output debug log
+		logger(ctx).Debugf("weights: %+v\n", weights)
+
+		results = pickWeighted(users, weights, num)
+	} else {
+		// Select the specified number of choices at random
+		selectedMap := map[string]struct{}{}
+		for {
+			selectedMap[users[rand.Intn(len(users))]] = struct{}{}
+			if len(selectedMap) == num {
+				break
+			}
+		}
+
+		// Output debug log
+		logger(ctx).Debugf("selectedMap: %+v\n", selectedMap)
+
+		for k := range selectedMap {
+			results = append(results, k)
+		}
+	}
+
+	c.recordHitHistory(ctx, aws, results)
+
+	// Notify your slack of the results
+	return sc.notifyHitSuccess(ctx, c, results)
+}
+
+// Every selection is kept, so that --fair can be used later.
+// The result is still notified if it could not be kept.
+func (c *commandParameter) recordHitHistory(ctx context.Context, aws awsServices, users []string) {
+	if !stateEnabled() {
+		return
+	}
+
+	history := &hitHistory{}
+	err := updateState(ctx, aws, stateKey("hit", c.channel), history, func() error {
+		history.Rounds = append(history.Rounds, &hitRound{Time: time.Now().Unix(), Users: users})
+		if len(history.Rounds) > maxHitHistory {
+			history.Rounds = history.Rounds[len(history.Rounds)-maxHitHistory:]
+		}
+		return nil
+	})
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to record the history of hit: ", err)
+	}
+}
+
+// The weight of a member is the number of selections since the member was last selected,
+// counting only the last window selections, so those not selected within the window weigh the most.
+func fairWeights(users []string, history *hitHistory, window int) map[string]int {
+	weights := map[string]int{}
+	for _, u := range users {
+		weights[u] = window + 1
+	}
+
+	age := 0
+	for i := len(history.Rounds) - 1; i >= 0 && age < window; i-- {
+		age++
+		for _, u := range history.Rounds[i].Users {
+			if w, ok := weights[u]; ok && age < w {
+				weights[u] = age
+			}
+		}
+	}
+
+	return weights
+}
+
+// Select num users without duplicates, each with a probability proportional to its weight
+func pickWeighted(users []string, weights map[string]int, num int) []string {
+	candidates := append([]string{}, users...)
+	results := []string{}
+	for len(results) < num && len(candidates) > 0 {
+		total := 0
+		for _, u := range candidates {
+			total += weights[u]
+		}
+
+		n := rand.Intn(total)
+		for i, u := range candidates {
+			n -= weights[u]
+			if n < 0 {
+				results = append(results, u)
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"github.com/google/uuid"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "link",
+		description: "Upload the attached file to Amazon S3 and generate a pre-signed URL",
+		notes: []string{
+			"Attach one or more files to the message",
+			"A pre-signed URL is generated per attachment",
+		},
+		examples: []string{
+			"link <file1>",
+			"link 15 <fileA, fileB>",
+		},
+		arguments: []*argumentSpec{
+			// AWS Security Token Service (STS) corresponds to a maximum of 36 hours
+			{name: "minutes", kind: intValue, defaultValue: "15", min: 1, max: 2160, description: "Expiry minutes"},
+		},
+		attachments: true,
+		handler:     (*commandParameter).runLinkCommand,
+	})
+}
+
+func (c *commandParameter) runLinkCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Getting information on environment variables
+	bucket := envconf.S3BucketName
+
+	// Generate a random UUID V4
+	uuid4, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	// Get a validity period
+	// The default is 15 minutes.
+	min := c.intArgument("minutes")
+
+	// There is nothing to do without attachments
+	if len(c.files) == 0 {
+		return errors.New("There are no attachments")
+	}
+
+	var results []*storage.S3Item
+	for k, v := range c.files {
+		// Output debug log
+		logger(ctx).Debugf("downlodURL: %+v\n", k)
+
+		// Downloading files from slack
+		wb, err := sc.chat.downloadFile(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		key := path.Join(uuid4.String(), v)
+
+		// Output debug log
+		logger(ctx).Debugf("bucket: %+v\n", bucket)
+		logger(ctx).Debugf("key: %+v\n", key)
+
+		// Upload file and create pre-signed URL
+		s3Item, err := aws.uploadAndPreSignedURL(ctx, bucket, key, wb, min)
+		if err != nil {
+			return err
+		}
+		results = append(results, s3Item)
+	}
+
+	// Notify your slack of the results
+	return sc.notifyLinkSuccess(ctx, c, results)
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "order",
+		description: "Put all the members in the channel in a random order",
+		notes: []string{
+			"With --slot, each member is given a start time, from now or from --start",
+		},
+		examples: []string{
+			"order",
+			"order --slot 2m --ex @userA",
+			"order --slot 90s --start 09:30",
+		},
+		options: []*optionSpec{
+			{name: "--ex", kind: userValue, repeatable: true, description: "Member to be excluded"},
+			{name: "--slot", kind: textValue, description: "Time given to each member, such as 2m or 90s"},
+			{name: "--start", kind: textValue, description: "Start time of the first member in JST, such as 09:30"},
+		},
+		handler: (*commandParameter).runOrderCommand,
+	})
+}
+
+// The slots of all the members are shown in a day at most
+const maxOrderSlot = 24 * time.Hour
+
+func (c *commandParameter) runOrderCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Get the value of a command option
+	val, _ := c.options["--ex"]
+
+	var slot time.Duration
+	if v, ok := c.options["--slot"]; ok {
+		d, err := time.ParseDuration(v[0])
+		if err != nil || d < time.Second || d > maxOrderSlot {
+			text := fmt.Sprintf("Invalid value for --slot, must be a duration from 1s to 24h such as 2m: %s", v[0])
+			logger(ctx).Println("[ERROR] " + text)
+			return errors.New(text)
+		}
+		slot = d
+	}
+	start, hasStart := c.options["--start"]
+	if hasStart && slot == 0 {
+		text := "The --start option can only be used with --slot"
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	// Get the target users
+	users, err := sc.getTargetUsers(ctx, c.channel, val)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		text := "There are no members to put in order"
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	results := shuffledStrings(users)
+
+	// The start time of each member, and the end time of the last one
+	var starts []string
+	if slot > 0 {
+		// The slots start when the command was posted, or now if it was not posted to slack
+		dateStr := strings.Replace(c.eventTs, ".", "", -1)
+		if dateStr == "" {
+			dateStr = strconv.FormatInt(time.Now().Unix(), 10)
+		}
+		clock := ""
+		if hasStart {
+			clock = start[0]
+		}
+		starts, err = datetime.StartTimes(dateStr, clock, slot, len(results)+1)
+		if err != nil {
+			text := "Invalid value for --start, must be a time such as 09:30: " + clock
+			logger(ctx).Println("[ERROR] "+text, err)
+			return errors.New(text)
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("order: %+v starts: %+v\n", results, starts)
+
+	// Notify your slack of the results
+	return sc.notifyOrderSuccess(ctx, c, results, starts)
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "pair",
+		description: "Randomly pair the members in the channel for a chat",
+		notes: []string{
+			"When the number of members is odd, one of the groups has three members",
+			"Pairs from the recent rounds are avoided when the state table is configured",
+		},
+		examples: []string{
+			"pair",
+			"pair --dm --ex @userA",
+			"pair --window 10",
+		},
+		options: []*optionSpec{
+			{name: "--ex", kind: userValue, repeatable: true, description: "Member to be excluded"},
+			{name: "--dm", kind: boolValue, description: "Open a group DM for each pair with an introduction"},
+			{name: "--window", kind: intValue, defaultValue: "5", min: 1, max: maxPairHistory, description: "Number of past rounds whose pairs are avoided"},
+		},
+		handler: (*commandParameter).runPairCommand,
+	})
+}
+
+// Number of past rounds kept for each channel
+const maxPairHistory = 50
+
+// Number of random pairings tried to find one without repeated pairs
+const pairAttempts = 200
+
+// The past rounds of pair in a channel, the newest last
+type pairHistory struct {
+	Rounds []*pairRound `json:"rounds"`
+}
+
+type pairRound struct {
+	Time   int64      `json:"time"`
+	Groups [][]string `json:"groups"`
+}
+
+func (c *commandParameter) runPairCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Get the value of a command option
+	val, _ := c.options["--ex"]
+
+	// Get the target users
+	users, err := sc.getTargetUsers(ctx, c.channel, val)
+	if err != nil {
+		return err
+	}
+	if len(users) < 2 {
+		text := "There are not enough members to pair: " + strings.Join(users, ", ")
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	// Without the state table, the pairs are not remembered
+	history := &pairHistory{}
+	if stateEnabled() {
+		if _, err := loadState(ctx, aws, stateKey("pair", c.channel), history); err != nil {
+			return err
+		}
+	}
+	seen := recentPairs(history, c.intOption("--window"))
+
+	rand.Seed(time.Now().UnixNano())
+	groups, repeated := makePairs(users, seen)
+
+	// Output debug log
+	logger(ctx).Debugf("groups: %+v repeated: %d\n", groups, repeated)
+
+	// The group DMs are opened first, as nobody is notified until something is posted
+	dm := c.boolOption("--dm")
+	var channels []string
+	if dm {
+		for _, g := range groups {
+			ch, err := sc.chat.openConversation(ctx, g...)
+			if err != nil {
+				return err
+			}
+			channels = append(channels, ch)
+		}
+	}
+
+	// The round is recorded before the introductions, so that a rerun does not pair the members introduced already
+	if stateEnabled() {
+		err := updateState(ctx, aws, stateKey("pair", c.channel), history, func() error {
+			history.Rounds = append(history.Rounds, &pairRound{Time: time.Now().Unix(), Groups: groups})
+			if len(history.Rounds) > maxPairHistory {
+				history.Rounds = history.Rounds[len(history.Rounds)-maxPairHistory:]
+			}
+			return nil
+		})
+		if err != nil {
+			logger(ctx).Println("[ERROR] Failed to record the history of pair: ", err)
+		}
+	}
+
+	// Introduce the members of each group to each other, and report the groups that could not be introduced
+	var failed [][]string
+	for i, ch := range channels {
+		_, _, err := sc.chat.postMessage(ctx, ch, slack.MsgOptionBlocks(buildPairIntroMessage(c, groups[i]).Blocks...))
+		if err != nil {
+			logger(ctx).Println("[ERROR] Failed to post the introduction: ", ch, err)
+			failed = append(failed, groups[i])
+		}
+	}
+
+	// Notify your slack of the results
+	return sc.notifyPairSuccess(ctx, c, groups, repeated, failed)
+}
+
+func pairKey(a string, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+
+	return a + "|" + b
+}
+
+// The pairs in the last window rounds
+func recentPairs(history *pairHistory, window int) map[string]bool {
+	seen := map[string]bool{}
+	for i := len(history.Rounds) - 1; i >= 0 && i >= len(history.Rounds)-window; i-- {
+		for _, g := range history.Rounds[i].Groups {
+			for j := range g {
+				for k := j + 1; k < len(g); k++ {
+					seen[pairKey(g[j], g[k])] = true
+				}
+			}
+		}
+	}
+
+	return seen
+}
+
+// Pair the users at random, with a group of three when the number is odd.
+// Several pairings are tried and the one with the fewest pairs in seen is returned with that number.
+func makePairs(users []string, seen map[string]bool) ([][]string, int) {
+	var best [][]string
+	bestRepeated := -1
+	for i := 0; i < pairAttempts && bestRepeated != 0; i++ {
+		shuffled := shuffledStrings(users)
+
+		var groups [][]string
+		for j := 0; j+1 < len(shuffled); j += 2 {
+			groups = append(groups, []string{shuffled[j], shuffled[j+1]})
+		}
+		if len(shuffled)%2 == 1 {
+			last := len(groups) - 1
+			groups[last] = append(groups[last], shuffled[len(shuffled)-1])
+		}
+
+		repeated := 0
+		for _, g := range groups {
+			for j := range g {
+				for k := j + 1; k < len(g); k++ {
+					if seen[pairKey(g[j], g[k])] {
+						repeated++
+					}
+				}
+			}
+		}
+		if bestRepeated < 0 || repeated < bestRepeated {
+			best, bestRepeated = groups, repeated
+		}
+	}
+
+	// Keep the members of each group in a stable order
+	for _, g := range best {
+		sort.Strings(g)
+	}
+
+	return best, bestRepeated
+}
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"time"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "rotation",
+		description: "Show or change the order of a rotation used by hit --rotate",
+		notes: []string{
+			"show: Show the order and the next member",
+			"reset: Shuffle the members and start from the beginning",
+			"skip: Skip the next member",
+			"reorder: Put the specified members first, in that order, and start from the beginning",
+			"Members who join the channel are added to the end, and those who leave are removed",
+		},
+		examples: []string{
+			"rotation show standup",
+			"rotation skip standup",
+			"rotation reorder retro @userA @userB",
+		},
+		arguments: []*argumentSpec{
+			{name: "action", kind: textValue, required: true, description: "show, reset, skip or reorder"},
+			{name: "name", kind: textValue, required: true, description: "Name of the rotation"},
+			{name: "members", kind: userValue, rest: true, description: "Members in the new order, for reorder"},
+		},
+		handler: (*commandParameter).runRotationCommand,
+	})
+}
+
+var rotationNamePattern = regexp.MustCompile(`^[0-9A-Za-z_-]{1,32}$`)
+
+// The order of the members in a rotation of a channel
+type rotationState struct {
+	Order []string `json:"order"`
+	// Index of the next member in the order
+	Next int `json:"next"`
+}
+
+func checkRotationName(name string) error {
+	if !rotationNamePattern.MatchString(name) {
+		return fmt.Errorf("The name of a rotation can only have up to 32 letters, numbers, \"-\" and \"_\": %s", name)
+	}
+
+	return nil
+}
+
+func (c *commandParameter) runRotationCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	action := c.arguments["action"]
+	name := c.arguments["name"]
+	if err := checkRotationName(name); err != nil {
+		return err
+	}
+	if !stateEnabled() {
+		logger(ctx).Println("[ERROR] " + errStateNotConfigured.Error())
+		return errStateNotConfigured
+	}
+
+	// Get the target users
+	members, err := sc.getTargetUsers(ctx, c.channel, nil)
+	if err != nil {
+		return err
+	}
+
+	var reordered []string
+	switch action {
+	case "show", "reset", "skip":
+		if c.arguments["members"] != "" {
+			return fmt.Errorf("Members can only be specified for reorder: %s", c.arguments["members"])
+		}
+	case "reorder":
+		reordered, err = parseMentions(c.arguments["members"])
+		if err != nil {
+			return err
+		}
+		if len(reordered) == 0 {
+			return errors.New("Specify the members in the new order, such as: rotation reorder " + name + " @userA @userB")
+		}
+		for _, u := range reordered {
+			if !containsString(members, u) {
+				return fmt.Errorf("Not a member of the channel: <@%s>", u)
+			}
+		}
+	default:
+		return fmt.Errorf("Unknown action for rotation, use show, reset, skip or reorder: %s", action)
+	}
+
+	key := stateKey("rotation", c.channel, name)
+	state := &rotationState{}
+	if action == "show" {
+		// The members are synchronized only when the rotation is changed
+		if _, err := loadState(ctx, aws, key, state); err != nil {
+			return err
+		}
+		rand.Seed(time.Now().UnixNano())
+		state.sync(members)
+	} else {
+		rand.Seed(time.Now().UnixNano())
+		err = updateState(ctx, aws, key, state, func() error {
+			switch action {
+			case "reset":
+				state.Order = nil
+				state.sync(members)
+				state.Next = 0
+			case "skip":
+				state.sync(members)
+				if len(state.Order) > 0 {
+					state.Next = (state.Next + 1) % len(state.Order)
+				}
+			case "reorder":
+				state.sync(members)
+				state.reorder(reordered)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("rotation: %s %+v\n", key, state)
+
+	// Notify your slack of the results
+	return sc.notifyRotationSuccess(ctx, c, name, state)
+}
+
+// Take the next num members of the rotation who are eligible, and advance it past the last of them
+func advanceRotation(ctx context.Context, aws awsServices, channel string, name string, members []string, eligible []string, num int) ([]string, error) {
+	rand.Seed(time.Now().UnixNano())
+
+	state := &rotationState{}
+	var picked []string
+	err := updateState(ctx, aws, stateKey("rotation", channel, name), state, func() error {
+		state.sync(members)
+		picked = state.pick(eligible, num)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("rotation: %s %+v\n", name, state)
+
+	return picked, nil
+}
+
+// Remove the members who left the channel, and add those who joined in random order at the end
+func (r *rotationState) sync(members []string) {
+	order := []string{}
+	for i, u := range r.Order {
+		if containsString(members, u) {
+			order = append(order, u)
+		} else if i < r.Next {
+			r.Next--
+		}
+	}
+
+	var joined []string
+	for _, u := range members {
+		if !containsString(order, u) {
+			joined = append(joined, u)
+		}
+	}
+	rand.Shuffle(len(joined), func(i, j int) {
+		joined[i], joined[j] = joined[j], joined[i]
+	})
+
+	r.Order = append(order, joined...)
+	if r.Next < 0 || r.Next >= len(r.Order) {
+		r.Next = 0
+	}
+}
+
+// The members who are not eligible, such as those excluded with --ex, lose their turn
+func (r *rotationState) pick(eligible []string, num int) []string {
+	picked := []string{}
+	last := -1
+	for i := 0; i < len(r.Order) && len(picked) < num; i++ {
+		idx := (r.Next + i) % len(r.Order)
+		if containsString(eligible, r.Order[idx]) {
+			picked = append(picked, r.Order[idx])
+			last = idx
+		}
+	}
+	if last >= 0 {
+		r.Next = (last + 1) % len(r.Order)
+	}
+
+	return picked
+}
+
+func (r *rotationState) reorder(first []string) {
+	order := append([]string{}, first...)
+	for _, u := range r.Order {
+		if !containsString(first, u) {
+			order = append(order, u)
+		}
+	}
+	r.Order = order
+	r.Next = 0
+}
+
+// The user IDs of the mentions in the text, such as "<@U0001> <@U0002>"
+func parseMentions(text string) ([]string, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, t := range tokens {
+		if t.kind != userToken {
+			return nil, fmt.Errorf("Not a mention: %s", t.value)
+		}
+		if !containsString(ids, t.value) {
+			ids = append(ids, t.value)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+)
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "short",
+		description: "Generate a shortened URL",
+		notes: []string{
+			"Depending on the timing of the deletion, the expiry date may be exceeded",
+		},
+		examples: []string{
+			"short https://aws.amazon.com/jp/",
+			"short https://aws.amazon.com/jp/ --ttl 7",
+		},
+		arguments: []*argumentSpec{
+			{name: "url", kind: urlValue, required: true, description: "URL to be shortened"},
+		},
+		options: []*optionSpec{
+			{name: "--ttl", kind: intValue, defaultValue: "1", min: 1, description: "Expiry days"},
+		},
+		handler: (*commandParameter).runShortCommand,
+	})
+}
+
+func (c *commandParameter) runShortCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	// Getting information on environment variables
+	table := envconf.URLTableName
+	baseURL := envconf.APIBaseURL
+
+	// Generate a random UUID V4
+	uuid4, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	// Create a unique id (take first 8 chars)
+	// https://github.com/aws-samples/aws-cdk-examples/blob/bb182579313dca6a91c630a116fc66cc3921f412/python/url-shortener/lambda/handler.py#L40
+	urlID := uuid4.String()[:8]
+
+	// Parse the URLs for the API and create shortened URLs
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, urlID)
+
+	// Output debug log
+	logger(ctx).Debugf("u: %+v\n", u.String())
+
+	// Get the value of a command option
+	// TTL is 1 day by default.
+	ttl := c.intOption("--ttl")
+
+	// Output debug log
+	logger(ctx).Debugf("ttl: %+v\n", ttl)
+
+	// Setting Information in the Mapping Table
+	unixTime, err := aws.putURLItem(ctx, table, urlID, c.arguments["url"], ttl)
+	if err != nil {
+		return err
+	}
+
+	// Get an expiration date for display
+	dateStr := strconv.FormatInt(unixTime, 10)
+	dateStr, _ = datetime.DisplayDateString(dateStr, "")
+
+	// Notify your slack of the results
+	return sc.notifyShortSuccess(ctx, c, u.String(), dateStr)
+}
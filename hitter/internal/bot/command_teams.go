@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Options to split the members into teams, shared with hit
+var teamOptions = []*optionSpec{
+	{name: "--size", kind: intValue, min: 1, description: "Make teams of at most this number of members"},
+	{name: "--apart", kind: userValue, repeatable: true, description: "Members to be put in different teams"},
+	{name: "--together", kind: userValue, repeatable: true, description: "Members to be put in the same team"},
+}
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "teams",
+		description: "Randomly split the members in the channel into teams",
+		notes: []string{
+			"Specify either the number of teams or --size",
+			"The teams differ in size by at most one member",
+		},
+		examples: []string{
+			"teams 4",
+			"teams --size 3 --ex @userA",
+			"teams 2 --apart @userA --apart @userB --together @userC --together @userD",
+		},
+		arguments: []*argumentSpec{
+			{name: "number", kind: intValue, min: 1, description: "Number of teams"},
+		},
+		options: append([]*optionSpec{
+			{name: "--ex", kind: userValue, repeatable: true, description: "Member to be excluded"},
+		}, teamOptions...),
+		handler: (*commandParameter).runTeamsCommand,
+	})
+}
+
+func (c *commandParameter) runTeamsCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	return c.splitTeams(ctx, sc, c.intArgument("number"))
+}
+
+// Split the members into the number of teams, or into teams of --size
+func (c *commandParameter) splitTeams(ctx context.Context, sc *slackClient, number int) error {
+	size := c.intOption("--size")
+	if (number == 0) == (size == 0) {
+		text := "Specify either the number of teams or --size"
+		logger(ctx).Println("[ERROR] " + text)
+		return errors.New(text)
+	}
+
+	// Get the value of a command option
+	val, _ := c.options["--ex"]
+
+	// Get the target users
+	users, err := sc.getTargetUsers(ctx, c.channel, val)
+	if err != nil {
+		return err
+	}
+
+	if size > 0 {
+		number = (len(users) + size - 1) / size
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	teams, err := makeTeams(users, number, c.options["--apart"], c.options["--together"])
+	if err != nil {
+		logger(ctx).Println("[ERROR] " + err.Error())
+		return err
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("teams: %+v\n", teams)
+
+	// Notify your slack of the results
+	return sc.notifyTeamsSuccess(ctx, c, teams)
+}
+
+// Partition the users into balanced teams at random.
+// The members of apart are put in different teams, and the members of together in the same team.
+func makeTeams(users []string, number int, apart []string, together []string) ([][]string, error) {
+	if number < 1 || len(users) < number {
+		return nil, fmt.Errorf("There are too many teams: %d/%d", number, len(users))
+	}
+	for _, u := range append(append([]string{}, apart...), together...) {
+		if !containsString(users, u) {
+			return nil, fmt.Errorf("Not a member of the channel, or excluded: <@%s>", u)
+		}
+	}
+
+	apart = uniqueStrings(apart)
+	together = uniqueStrings(together)
+	if len(apart) > number {
+		return nil, fmt.Errorf("There are more members to be put apart than teams: %d/%d", len(apart), number)
+	}
+	// The largest team has this many members
+	capacity := (len(users) + number - 1) / number
+	if len(together) > capacity {
+		return nil, fmt.Errorf("There are more members to be put together than the size of a team: %d/%d", len(together), capacity)
+	}
+	shared := 0
+	for _, u := range together {
+		if containsString(apart, u) {
+			shared++
+		}
+	}
+	if shared > 1 {
+		return nil, errors.New("Members to be put apart cannot also be put together")
+	}
+
+	// The first teams have one more member, until the members are divided evenly
+	sizes := make([]int, number)
+	for i := range sizes {
+		sizes[i] = len(users) / number
+		if i < len(users)%number {
+			sizes[i]++
+		}
+	}
+
+	teams := make([][]string, number)
+	placed := map[string]bool{}
+	add := func(i int, u string) {
+		teams[i] = append(teams[i], u)
+		placed[u] = true
+	}
+
+	// The members put together go to the first team, which is one of the largest
+	for _, u := range together {
+		add(0, u)
+	}
+
+	// Each of the members put apart goes to a team without another one,
+	// and to the team of the members put together only when there is no other team left
+	order := []int{}
+	for i := 1; i < number; i++ {
+		order = append(order, i)
+	}
+	if len(together) == 0 {
+		order = append([]int{0}, order...)
+	} else if shared == 0 {
+		order = append(order, 0)
+	}
+	k := 0
+	for _, u := range shuffledStrings(apart) {
+		if placed[u] {
+			continue
+		}
+		add(order[k], u)
+		k++
+	}
+
+	// The others fill the remaining places at random
+	free := []int{}
+	for i, n := range sizes {
+		if len(teams[i]) > n {
+			return nil, errors.New("The members cannot be put apart and together in teams of this size")
+		}
+		for j := len(teams[i]); j < n; j++ {
+			free = append(free, i)
+		}
+	}
+	rest := []string{}
+	for _, u := range users {
+		if !placed[u] {
+			rest = append(rest, u)
+		}
+	}
+	for k, u := range shuffledStrings(rest) {
+		add(free[k], u)
+	}
+
+	// Do not let the order tell which team was filled first
+	rand.Shuffle(len(teams), func(i, j int) {
+		teams[i], teams[j] = teams[j], teams[i]
+	})
+	for _, t := range teams {
+		rand.Shuffle(len(t), func(i, j int) {
+			t[i], t[j] = t[j], t[i]
+		})
+	}
+
+	return teams, nil
+}
+
+func uniqueStrings(list []string) []string {
+	result := []string{}
+	for _, s := range list {
+		if !containsString(result, s) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+func shuffledStrings(list []string) []string {
+	result := append([]string{}, list...)
+	rand.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+
+	return result
+}
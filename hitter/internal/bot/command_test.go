@@ -0,0 +1,855 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/config"
+	"github.com/uchimanajet7/hitter/hitter/internal/slacktest"
+)
+
+func setupCommandTest(t *testing.T) (*slacktest.Server, *slackClient, *memoryAWS) {
+	t.Helper()
+
+	envconf = &envConfig{
+		Common:       config.Common{URLTableName: "HitterURLTable"},
+		S3BucketName: "hitter-bucket",
+		APIBaseURL:   "https://short.example.com/v1/",
+	}
+
+	server := slacktest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser("C0123", slack.User{ID: "U0001"})
+	server.AddUser("C0123", slack.User{ID: "U0002"})
+	server.AddUser("C0123", slack.User{ID: "U0003"})
+	server.AddUser("C0123", slack.User{ID: "B0001", IsBot: true})
+
+	return server, newSlackClient("xoxb-test", server.APIURL()), newMemoryAWS()
+}
+
+func newTestCommand(text string) *commandParameter {
+	c := &commandParameter{}
+	c.channel = "C0123"
+	c.eventTs = "1595673533.002100"
+	c.from = "U0001"
+	c.text = "<@UHITTERBOT> " + text
+	c.command, c.body = splitHead(text)
+
+	return c
+}
+
+// The text of all the sections in the message
+func messageText(msg *slack.Msg) string {
+	var texts []string
+	for _, b := range msg.Blocks.BlockSet {
+		if s, ok := b.(*slack.SectionBlock); ok && s.Text != nil {
+			texts = append(texts, s.Text.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+func lastMessage(t *testing.T, server *slacktest.Server) *slack.Msg {
+	t.Helper()
+
+	msgs, err := server.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) == 0 {
+		t.Fatal("no messages were posted")
+	}
+
+	return msgs[len(msgs)-1]
+}
+
+func TestRunHitCommand(t *testing.T) {
+	tests := []struct {
+		text     string
+		contains []string
+		excludes []string
+	}{
+		{text: "hit 3", contains: []string{"<@U0001>", "<@U0002>", "<@U0003>"}, excludes: []string{"B0001"}},
+		{text: "hit --ex <@U0001> --ex <@U0002>", contains: []string{"<@U0003> You are the *1th* choice"}},
+		{text: "hit 2 --ex <@U0001> --ex <@U0002>", contains: []string{"There are too many choices: 2/1"}},
+		{text: "hit 0", contains: []string{"Invalid value for <number>, must be at least 1", "^"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			server, sc, aws := setupCommandTest(t)
+
+			err := newTestCommand(tt.text).runCommand(context.Background(), sc, aws)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			text := messageText(lastMessage(t, server))
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("message does not contain %q:\n%s", s, text)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(text, s) {
+					t.Errorf("message contains %q:\n%s", s, text)
+				}
+			}
+		})
+	}
+}
+
+func TestRunHitCommandCalls(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	err := newTestCommand("hit 2").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"conversations.members", "users.info", "chat.postMessage"}
+	if got := server.Methods(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := server.Calls("chat.postMessage")[0].Values.Get("channel"); got != "C0123" {
+		t.Errorf("posted to %q", got)
+	}
+}
+
+func TestRunCommandSlackError(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	server.FailMethod("conversations.members", "channel_not_found")
+
+	err := newTestCommand("hit").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := messageText(lastMessage(t, server))
+	if !strings.Contains(text, "channel_not_found") {
+		t.Errorf("the error is not reported:\n%s", text)
+	}
+}
+
+// The text of the sections in the message posted with fakeChat
+func fakeMessageText(t *testing.T, msg *fakeMessage) string {
+	t.Helper()
+
+	blocks := slack.Blocks{}
+	if err := json.Unmarshal([]byte(msg.values.Get("blocks")), &blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	return messageText(&slack.Msg{Blocks: blocks})
+}
+
+func TestRunCommandWithFakes(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		setup func(chat *fakeChat, members *fakeMembers) map[string]string
+		// Contained in the last message
+		wantText          []string
+		wantMessages      int
+		wantConversations int
+		wantUploads       int
+	}{
+		{
+			name:         "bots are not selected",
+			text:         "hit 3",
+			wantText:     []string{"<@U0001>", "<@U0002>", "<@U0003>"},
+			wantMessages: 1,
+		},
+		{
+			name:         "too many choices",
+			text:         "hit 4",
+			wantText:     []string{"There are too many choices: 4/3"},
+			wantMessages: 1,
+		},
+		{
+			name:         "teams",
+			text:         "teams 3",
+			wantText:     []string{"*Team 1:*", "*Team 2:*", "*Team 3:*"},
+			wantMessages: 1,
+		},
+		{
+			name: "pair in group DMs",
+			text: "pair --dm",
+			setup: func(chat *fakeChat, members *fakeMembers) map[string]string {
+				members.channels["C0123"] = append(members.channels["C0123"], "U0004")
+				return nil
+			},
+			wantText:          []string{":coffee:", "Please find a time that works for both of you."},
+			wantMessages:      3,
+			wantConversations: 2,
+		},
+		{
+			name: "link",
+			text: "link 30",
+			setup: func(chat *fakeChat, members *fakeMembers) map[string]string {
+				chat.files["https://files.example.com/F0001"] = []byte("file content")
+				return map[string]string{"https://files.example.com/F0001": "report.txt"}
+			},
+			wantText:     []string{":linked_paperclips: S3 Object information and Pre-Signed URL"},
+			wantMessages: 1,
+			wantUploads:  1,
+		},
+		{
+			name: "link to a missing file",
+			text: "link",
+			setup: func(chat *fakeChat, members *fakeMembers) map[string]string {
+				return map[string]string{"https://files.example.com/F0002": "report.txt"}
+			},
+			wantText:     []string{"file not found: https://files.example.com/F0002"},
+			wantMessages: 1,
+		},
+		{
+			name: "members error",
+			text: "hit",
+			setup: func(chat *fakeChat, members *fakeMembers) map[string]string {
+				members.err = errors.New("channel_not_found")
+				return nil
+			},
+			wantText:     []string{"Command execution failed. *[channel_not_found]*"},
+			wantMessages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCommandTest(t)
+			chat := newFakeChat()
+			members := newFakeMembers()
+			members.channels["C0123"] = []string{"U0001", "U0002", "U0003", "B0001"}
+			members.bots["B0001"] = true
+
+			c := newTestCommand(tt.text)
+			if tt.setup != nil {
+				c.files = tt.setup(chat, members)
+			}
+			err := c.runCommand(context.Background(), newSlackClientWith(chat, members), newMemoryAWS())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(chat.messages) != tt.wantMessages || len(chat.conversations) != tt.wantConversations || len(chat.uploads) != tt.wantUploads {
+				t.Fatalf("got %d messages, %d conversations and %d uploads", len(chat.messages), len(chat.conversations), len(chat.uploads))
+			}
+			text := fakeMessageText(t, chat.messages[len(chat.messages)-1])
+			for _, s := range tt.wantText {
+				if !strings.Contains(text, s) {
+					t.Errorf("message does not contain %q:\n%s", s, text)
+				}
+			}
+			if strings.Contains(text, "B0001") {
+				t.Errorf("the bot is in the message:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestRunCommandChatError(t *testing.T) {
+	setupCommandTest(t)
+	chat := newFakeChat()
+	chat.err = errors.New("not_in_channel")
+	members := newFakeMembers()
+	members.channels["C0123"] = []string{"U0001"}
+
+	// The result cannot be reported, so the error is returned
+	err := newTestCommand("hit").runCommand(context.Background(), newSlackClientWith(chat, members), newMemoryAWS())
+	if err == nil || err.Error() != "not_in_channel" {
+		t.Errorf("runCommand() error = %v", err)
+	}
+}
+
+func TestRunHitCommandFair(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	// The history is required for --fair
+	err := newTestCommand("hit --fair").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := messageText(lastMessage(t, server)); !strings.Contains(text, "STATE_TABLE_NAME") {
+		t.Errorf("the missing table is not reported:\n%s", text)
+	}
+
+	envconf.StateTableName = "HitterStateTable"
+	counts := map[string]int{}
+	for i := 0; i < 30; i++ {
+		err := newTestCommand("hit --fair --window 2").runCommand(context.Background(), sc, aws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range sc.result.Result.(*hitResult).Users {
+			counts[u]++
+		}
+	}
+	for _, u := range []string{"U0001", "U0002", "U0003"} {
+		if counts[u] == 0 {
+			t.Errorf("%s was never selected: %v", u, counts)
+		}
+	}
+
+	history := &hitHistory{}
+	if _, err := loadState(context.Background(), aws, stateKey("hit", "C0123"), history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Rounds) != 30 {
+		t.Errorf("got %d rounds in the history", len(history.Rounds))
+	}
+}
+
+func hitUsers(t *testing.T, sc *slackClient) []string {
+	t.Helper()
+
+	if sc.result == nil || sc.result.Error != "" {
+		t.Fatalf("the command failed: %+v", sc.result)
+	}
+
+	return sc.result.Result.(*hitResult).Users
+}
+
+func TestRunHitCommandRotate(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	envconf.StateTableName = "HitterStateTable"
+
+	// Every member is selected once before anyone is selected again
+	var picked []string
+	for i := 0; i < 4; i++ {
+		err := newTestCommand("hit --rotate standup").runCommand(context.Background(), sc, aws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked = append(picked, hitUsers(t, sc)...)
+	}
+	if picked[0] == picked[1] || picked[1] == picked[2] || picked[0] == picked[2] || picked[3] != picked[0] {
+		t.Errorf("not in turn: %v", picked)
+	}
+
+	// The excluded member loses the turn
+	err := newTestCommand("hit --rotate standup --ex <@"+picked[1]+">").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitUsers(t, sc); got[0] != picked[2] {
+		t.Errorf("got %v, want %s", got, picked[2])
+	}
+
+	// A member who joins is added to the rotation
+	server.AddUser("C0123", slack.User{ID: "U0004"})
+	err = newTestCommand("hit 4 --rotate standup").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitUsers(t, sc); len(got) != 4 || !containsString(got, "U0004") {
+		t.Errorf("got %v", got)
+	}
+
+	err = newTestCommand("hit --rotate standup --fair").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := messageText(lastMessage(t, server)); !strings.Contains(text, "cannot be used together") {
+		t.Errorf("the conflict is not reported:\n%s", text)
+	}
+}
+
+func TestRunRotationCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	envconf.StateTableName = "HitterStateTable"
+
+	run := func(text string) *rotationResult {
+		t.Helper()
+		err := newTestCommand(text).runCommand(context.Background(), sc, aws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc.result == nil || sc.result.Error != "" {
+			t.Fatalf("%s failed: %+v", text, sc.result)
+		}
+		return sc.result.Result.(*rotationResult)
+	}
+
+	got := run("rotation reorder retro <@U0003> <@U0001>")
+	if !reflect.DeepEqual(got.Order, []string{"U0003", "U0001", "U0002"}) || got.Next != "U0003" {
+		t.Errorf("reorder: %+v", got)
+	}
+	if got := run("rotation skip retro"); got.Next != "U0001" {
+		t.Errorf("skip: %+v", got)
+	}
+	err := newTestCommand("hit --rotate retro").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitUsers(t, sc); got[0] != "U0001" {
+		t.Errorf("hit after skip: %v", got)
+	}
+	if got := run("rotation show retro"); got.Next != "U0002" {
+		t.Errorf("show: %+v", got)
+	}
+	if got := run("rotation reset retro"); len(got.Order) != 3 || got.Next != got.Order[0] {
+		t.Errorf("reset: %+v", got)
+	}
+
+	for text, want := range map[string]string{
+		"rotation reorder retro <@U0009>": "Not a member of the channel: <@U0009>",
+		"rotation rewind retro":           "Unknown action for rotation",
+		"rotation show retro#1":           "The name of a rotation can only have",
+	} {
+		err := newTestCommand(text).runCommand(context.Background(), sc, aws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := messageText(lastMessage(t, server)); !strings.Contains(msg, want) {
+			t.Errorf("%s: the error is not reported:\n%s", text, msg)
+		}
+	}
+}
+
+func TestRotationSync(t *testing.T) {
+	r := &rotationState{Order: []string{"U0001", "U0002", "U0003", "U0004"}, Next: 2}
+
+	// U0002 left, so the next member is still U0003
+	r.sync([]string{"U0001", "U0003", "U0004", "U0005"})
+	if !reflect.DeepEqual(r.Order, []string{"U0001", "U0003", "U0004", "U0005"}) || r.Next != 1 {
+		t.Errorf("got %+v", r)
+	}
+
+	got := r.pick([]string{"U0001", "U0004", "U0005"}, 2)
+	if !reflect.DeepEqual(got, []string{"U0004", "U0005"}) || r.Next != 0 {
+		t.Errorf("got %v, %+v", got, r)
+	}
+}
+
+func TestRunTeamsCommand(t *testing.T) {
+	tests := []struct {
+		text  string
+		sizes []int
+		err   string
+	}{
+		{text: "teams 2", sizes: []int{1, 2}},
+		{text: "teams --size 2 --ex <@U0003>", sizes: []int{2}},
+		{text: "hit --teams 3", sizes: []int{1, 1, 1}},
+		{text: "hit --size 1 --apart <@U0001> --apart <@U0002>", sizes: []int{1, 1, 1}},
+		{text: "teams", err: "Specify either the number of teams or --size"},
+		{text: "teams 4", err: "There are too many teams: 4/3"},
+		{text: "hit --apart <@U0001>", err: "can only be used with --teams or --size"},
+		{text: "hit --teams 2 --rotate standup", err: "cannot be used with --fair or --rotate"},
+		{text: "hit 2 --teams 2", err: "The number of selections cannot be used with --teams or --size"},
+		{text: "hit 1 --size 2", err: "The number of selections cannot be used with --teams or --size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			server, sc, aws := setupCommandTest(t)
+
+			err := newTestCommand(tt.text).runCommand(context.Background(), sc, aws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" {
+				if text := messageText(lastMessage(t, server)); !strings.Contains(text, tt.err) {
+					t.Errorf("the error is not reported:\n%s", text)
+				}
+				return
+			}
+
+			result := sc.result.Result.(*teamsResult)
+			var sizes []int
+			for _, team := range result.Teams {
+				sizes = append(sizes, len(team))
+			}
+			sort.Ints(sizes)
+			if !reflect.DeepEqual(sizes, tt.sizes) {
+				t.Errorf("got teams %v, want sizes %v", result.Teams, tt.sizes)
+			}
+		})
+	}
+}
+
+func TestMakeTeams(t *testing.T) {
+	users := []string{"U0001", "U0002", "U0003", "U0004", "U0005", "U0006", "U0007"}
+	teamOf := func(teams [][]string, u string) int {
+		for i, team := range teams {
+			if containsString(team, u) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// The result is random, so check it many times
+	for i := 0; i < 100; i++ {
+		teams, err := makeTeams(users, 3, []string{"U0001", "U0002", "U0003"}, []string{"U0003", "U0004", "U0005"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, team := range teams {
+			if len(team) < 2 || len(team) > 3 {
+				t.Fatalf("not balanced: %v", teams)
+			}
+			count += len(team)
+		}
+		if count != len(users) {
+			t.Fatalf("got %d members: %v", count, teams)
+		}
+		a, b, c := teamOf(teams, "U0001"), teamOf(teams, "U0002"), teamOf(teams, "U0003")
+		if a == b || b == c || a == c {
+			t.Fatalf("not apart: %v", teams)
+		}
+		if teamOf(teams, "U0004") != c || teamOf(teams, "U0005") != c {
+			t.Fatalf("not together: %v", teams)
+		}
+	}
+
+	errs := []struct {
+		number   int
+		apart    []string
+		together []string
+		want     string
+	}{
+		{number: 2, apart: []string{"U0001", "U0002", "U0003"}, want: "more members to be put apart than teams"},
+		{number: 3, together: []string{"U0001", "U0002", "U0003", "U0004"}, want: "more members to be put together than the size of a team"},
+		{number: 3, apart: []string{"U0001", "U0002"}, together: []string{"U0001", "U0002"}, want: "cannot also be put together"},
+		{number: 2, apart: []string{"U0009"}, want: "Not a member of the channel"},
+		{number: 3, apart: []string{"U0001", "U0002", "U0003"}, together: []string{"U0004", "U0005", "U0006"}, want: "cannot be put apart and together"},
+	}
+	for _, tt := range errs {
+		_, err := makeTeams(users, tt.number, tt.apart, tt.together)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: got %v", tt, err)
+		}
+	}
+}
+
+func TestRunPairCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	// An odd number of members makes a group of three
+	err := newTestCommand("pair").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.result.Result.(*pairResult).Groups; !reflect.DeepEqual(got, [][]string{{"U0001", "U0002", "U0003"}}) {
+		t.Errorf("got %v", got)
+	}
+
+	err = newTestCommand("pair --ex <@U0001> --ex <@U0002>").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := messageText(lastMessage(t, server)); !strings.Contains(text, "There are not enough members to pair") {
+		t.Errorf("the error is not reported:\n%s", text)
+	}
+
+	// Four members can be paired in three ways, so no pair meets twice in three rounds
+	envconf.StateTableName = "HitterStateTable"
+	server.AddUser("C0123", slack.User{ID: "U0004"})
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		err := newTestCommand("pair --window 2").runCommand(context.Background(), sc, aws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := sc.result.Result.(*pairResult)
+		if result.Repeated != 0 || len(result.Groups) != 2 {
+			t.Fatalf("round %d: %+v", i+1, result)
+		}
+		for _, g := range result.Groups {
+			if seen[pairKey(g[0], g[1])] {
+				t.Errorf("round %d: %v met again", i+1, g)
+			}
+			seen[pairKey(g[0], g[1])] = true
+		}
+	}
+}
+
+func TestRunPairCommandDM(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	server.AddUser("C0123", slack.User{ID: "U0004"})
+
+	err := newTestCommand("pair --dm").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The group DMs are all opened before the introductions are posted
+	want := []string{"conversations.members", "users.info", "conversations.open", "conversations.open", "chat.postMessage", "chat.postMessage", "chat.postMessage"}
+	if got := server.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, g := range sc.result.Result.(*pairResult).Groups {
+		if got := server.Calls("conversations.open")[i].Values.Get("users"); got != strings.Join(g, ",") {
+			t.Errorf("opened with %q, want %v", got, g)
+		}
+		intro := server.Calls("chat.postMessage")[i]
+		if intro.Values.Get("channel") != "G"+strings.Join(g, "") {
+			t.Errorf("introduced in %q", intro.Values.Get("channel"))
+		}
+	}
+}
+
+func TestRunPairCommandDMFailure(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	server.AddUser("C0123", slack.User{ID: "U0004"})
+	envconf.StateTableName = "HitterStateTable"
+
+	// Nothing is posted nor recorded if the group DMs cannot be opened
+	server.FailMethod("conversations.open", "missing_scope")
+	err := newTestCommand("pair --dm").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Methods(); !reflect.DeepEqual(got, []string{"conversations.members", "users.info", "conversations.open", "chat.postMessage"}) {
+		t.Fatalf("got %v", got)
+	}
+	history := &pairHistory{}
+	if _, err := loadState(context.Background(), aws, stateKey("pair", "C0123"), history); err != nil || len(history.Rounds) != 0 {
+		t.Fatalf("history = %+v, %v", history, err)
+	}
+
+	// The introduction to the group of U0001 fails, and the other group is still introduced
+	server = slacktest.NewServer()
+	t.Cleanup(server.Close)
+	for _, u := range []string{"U0001", "U0002", "U0003", "U0004"} {
+		server.AddUser("C0123", slack.User{ID: u})
+		if u != "U0001" {
+			server.FailCall("chat.postMessage", "channel", "GU0001"+u, "cannot_dm_bot")
+		}
+	}
+	sc = newSlackClient("xoxb-test", server.APIURL())
+	err = newTestCommand("pair --dm").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := sc.result.Result.(*pairResult)
+	if len(result.Failed) != 1 || result.Failed[0][0] != "U0001" {
+		t.Errorf("failed = %v", result.Failed)
+	}
+	if got := len(server.Calls("chat.postMessage")); got != 3 {
+		t.Errorf("posted %d messages, want 2 introductions and the result", got)
+	}
+	if text := messageText(lastMessage(t, server)); !strings.Contains(text, "The introduction could not be sent to "+mentionList(result.Failed[0])) {
+		t.Errorf("the failure is not reported:\n%s", text)
+	}
+
+	// The round is recorded even though one of the introductions failed
+	if _, err := loadState(context.Background(), aws, stateKey("pair", "C0123"), history); err != nil || len(history.Rounds) != 1 {
+		t.Fatalf("history = %+v, %v", history, err)
+	}
+	if !reflect.DeepEqual(history.Rounds[0].Groups, result.Groups) {
+		t.Errorf("recorded %v, want %v", history.Rounds[0].Groups, result.Groups)
+	}
+}
+
+func TestRunOrderCommand(t *testing.T) {
+	tests := []struct {
+		text   string
+		starts []string
+		ends   string
+		err    string
+	}{
+		{text: "order"},
+		{text: "order --slot 2m --start 09:30", starts: []string{"09:30", "09:32", "09:34"}, ends: "09:36"},
+		{text: "order --slot 90s --start 09:30", starts: []string{"09:30:00", "09:31:30", "09:33:00"}, ends: "09:34:30"},
+		// The slots start when the command was posted
+		{text: "order --slot 5m", starts: []string{"19:38", "19:43", "19:48"}, ends: "19:53"},
+		{text: "order --slot soon", err: "Invalid value for --slot"},
+		{text: "order --slot 0s", err: "Invalid value for --slot"},
+		{text: "order --start 09:30", err: "The --start option can only be used with --slot"},
+		{text: "order --slot 2m --start 25:00", err: "Invalid value for --start"},
+		{text: "order --ex <@U0001> --ex <@U0002> --ex <@U0003>", err: "There are no members to put in order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			server, sc, aws := setupCommandTest(t)
+
+			err := newTestCommand(tt.text).runCommand(context.Background(), sc, aws)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != "" {
+				if text := messageText(lastMessage(t, server)); !strings.Contains(text, tt.err) {
+					t.Errorf("the error is not reported:\n%s", text)
+				}
+				return
+			}
+
+			result := sc.result.Result.(*orderResult)
+			users := append([]string{}, result.Users...)
+			sort.Strings(users)
+			if !reflect.DeepEqual(users, []string{"U0001", "U0002", "U0003"}) {
+				t.Errorf("got users %v", result.Users)
+			}
+			if !reflect.DeepEqual(result.Starts, tt.starts) || result.Ends != tt.ends {
+				t.Errorf("got starts %v ends %q, want %v %q", result.Starts, result.Ends, tt.starts, tt.ends)
+			}
+		})
+	}
+}
+
+func TestFairWeights(t *testing.T) {
+	history := &hitHistory{Rounds: []*hitRound{
+		{Users: []string{"U0003"}},
+		{Users: []string{"U0001"}},
+		{Users: []string{"U0002", "U0001"}},
+	}}
+
+	got := fairWeights([]string{"U0001", "U0002", "U0003", "U0004"}, history, 2)
+	want := map[string]int{"U0001": 1, "U0002": 1, "U0003": 3, "U0004": 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = fairWeights([]string{"U0001", "U0003"}, history, 10)
+	want = map[string]int{"U0001": 1, "U0003": 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRunShortCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	err := newTestCommand("short <https://aws.amazon.com/jp/> --ttl 7").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(aws.memoryURLStore.items) != 1 {
+		t.Fatalf("got %d url items", len(aws.memoryURLStore.items))
+	}
+	for key, item := range aws.memoryURLStore.items {
+		if item.URL != "https://aws.amazon.com/jp/" {
+			t.Errorf("got url %q", item.URL)
+		}
+		text := messageText(lastMessage(t, server))
+		if !strings.Contains(text, ":link: https://short.example.com/v1/"+item.ID) {
+			t.Errorf("the shortened URL is not posted for %s:\n%s", key, text)
+		}
+	}
+}
+
+func TestRunTranslateCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	err := newTestCommand("translate hello world").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"chat.postMessage", "auth.test", "files.upload"}
+	if got := server.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// The result is attached to the thread of the message
+	upload := server.Calls("files.upload")[0]
+	if upload.Values.Get("thread_ts") == "" || upload.Values.Get("channels") != "C0123" {
+		t.Errorf("the file is not uploaded to the thread: %v", upload.Values)
+	}
+	if !strings.Contains(string(upload.Body), "[ja] hello world") {
+		t.Errorf("the translation is not uploaded:\n%s", upload.Body)
+	}
+}
+
+func TestRunLinkCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	c := newTestCommand("link 30")
+	c.files = map[string]string{server.AddFile("F0001", []byte("file content")): "report.txt"}
+	err := c.runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{slacktest.FileDownload, "chat.postMessage", "auth.test", "files.upload"}
+	if got := server.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, body := range aws.memoryFileStore.objects {
+		if !strings.HasPrefix(key, "hitter-bucket/") || !strings.HasSuffix(key, "/report.txt") || string(body) != "file content" {
+			t.Errorf("got object %s: %s", key, body)
+		}
+	}
+	if !strings.Contains(string(server.Calls("files.upload")[0].Body), "X-Amz-Expires=1800") {
+		t.Errorf("the pre-signed URL is not uploaded")
+	}
+}
+
+func TestSlashCommandResponseURL(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	c := newTestCommand("translate --private hello")
+	c.responseURL = server.ResponseURL("T0001")
+	c.responseType = slack.ResponseTypeInChannel
+	err := c.runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Files cannot be attached to the response, so both are returned as messages
+	want := []string{slacktest.ResponseURL, slacktest.ResponseURL}
+	if got := server.Methods(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	msgs, err := server.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		if msg.ResponseType != slack.ResponseTypeEphemeral {
+			t.Errorf("got response type %q", msg.ResponseType)
+		}
+	}
+	if text := messageText(msgs[1]); !strings.Contains(text, "[ja] hello") {
+		t.Errorf("the translation is not returned:\n%s", text)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+	aws.fakeTranslator.delay = time.Minute
+
+	// The command is stopped ahead of the deadline, and the error is posted within the margin
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutMargin+50*time.Millisecond)
+	defer cancel()
+	err := newTestCommand("translate hello world").runCommand(ctx, sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.result.Error != errCommandTimedOut.Error() {
+		t.Errorf("got error %q", sc.result.Error)
+	}
+
+	text := messageText(lastMessage(t, server))
+	if !strings.Contains(text, "Command execution timed out.") {
+		t.Errorf("the timeout is not posted:\n%s", text)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	server, sc, aws := setupCommandTest(t)
+
+	err := newTestCommand("hti 2").runCommand(context.Background(), sc, aws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := messageText(lastMessage(t, server))
+	if !strings.Contains(text, "Did you mean *hit*?") {
+		t.Errorf("no suggestion:\n%s", text)
+	}
+}
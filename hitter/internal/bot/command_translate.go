@@ -0,0 +1,48 @@
+package bot
+
+import "context"
+
+func init() {
+	registerCommand(&commandSpec{
+		name:        "translate",
+		description: "Translates the input text",
+		notes: []string{
+			"Japanese is translated into English, and other languages into Japanese",
+			"The input text may span multiple lines",
+		},
+		examples: []string{
+			"translate AWS is the world’s most comprehensive and broadly adopted cloud platform",
+			"translate AWS は、世界で最も包括的で広く採用されているクラウドプラットフォームです",
+		},
+		arguments: []*argumentSpec{
+			{name: "text", kind: textValue, required: true, rest: true, description: "Text to be translated"},
+		},
+		handler: (*commandParameter).runTranslateCommand,
+	})
+}
+
+func (c *commandParameter) runTranslateCommand(ctx context.Context, sc *slackClient, aws awsServices) error {
+	text := c.arguments["text"]
+
+	// Get the language code of the input text.
+	source, err := aws.detectLanguageCode(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	// Determine the language code to translate
+	// https://docs.aws.amazon.com/ja_jp/translate/latest/dg/what-is.html#what-is-languages
+	target := "ja"
+	if source == "ja" {
+		target = "en"
+	}
+
+	// Translate the text
+	translated, err := aws.translate(ctx, text, source, target)
+	if err != nil {
+		return err
+	}
+
+	// Notify your slack of the results
+	return sc.notifyTranslateSuccess(ctx, c, text, translated, source, target)
+}
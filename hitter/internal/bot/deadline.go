@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Every call to slack and AWS is bounded by the deadline of the invocation,
+// so that a command can report that it timed out before AWS Lambda stops the function.
+
+// Time kept after the deadline of the command to post the error and record the outcome
+const timeoutMargin = 10 * time.Second
+
+var errCommandTimedOut = errors.New("The command did not finish within the time limit")
+
+// The command ends before the deadline of the invocation, or after COMMAND_TIMEOUT if there is none
+func commandContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(ctx, deadline.Add(-timeoutMargin))
+	}
+	if envconf != nil && envconf.CommandTimeout > 0 {
+		return context.WithTimeout(ctx, envconf.CommandTimeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// A single call ends after CALL_TIMEOUT, within the deadline of the command
+func callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if envconf != nil && envconf.CallTimeout > 0 {
+		return context.WithTimeout(ctx, envconf.CallTimeout)
+	}
+
+	return context.WithCancel(ctx)
+}
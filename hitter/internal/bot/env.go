@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/config"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// Keep information obtained from environment variables in global variables.
+var envconf *envConfig
+
+// The log with the fields of the request, such as the slack event ID
+func logger(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx)
+}
+
+type envConfig struct {
+	// URL_TABLE_NAME, DEBUG_LOG, LISTEN_ADDR and DYNAMODB_ENDPOINT are shared with the redirect function
+	config.Common
+
+	SlackOAuthAccessToken string `envconfig:"SLACK_OAUTH_ACCESS_TOKEN" required:"true"`
+	SlackSigningSecret    string `envconfig:"SLACK_SIGNING_SECRET"`
+	MutexTableName        string `envconfig:"MUTEX_TABLE_NAME" required:"true"`
+	S3BucketName          string `envconfig:"S3_BUCKET_NAME" required:"true"`
+	APIBaseURL            string `envconfig:"API_BASE_URL" required:"true"`
+	SlackChannelID        string `envconfig:"SLACK_CHANNEL_ID"`
+	// Only used to connect to a fake slack server in tests, such as "http://127.0.0.1:8080/api/"
+	SlackAPIURL string `envconfig:"SLACK_API_URL"`
+	// Only used for Socket Mode, the app-level token starting with "xapp-"
+	SlackAppToken string `envconfig:"SLACK_APP_TOKEN"`
+	// Visibility of the results of slash commands, "in_channel" or "ephemeral"
+	SlashResponseType string `envconfig:"SLASH_RESPONSE_TYPE" default:"in_channel"`
+	// "lambda" or "local", the default depends on whether it is running on AWS Lambda
+	QueueMode string `envconfig:"QUEUE_MODE"`
+	// Set by AWS Lambda, used to invoke the worker
+	FunctionName string `envconfig:"AWS_LAMBDA_FUNCTION_NAME"`
+	// Users allowed to run commands that require admin permission
+	AdminUserIDs []string `envconfig:"ADMIN_USER_IDS"`
+	// Only needed by the commands that remember their past results, such as "hit --fair"
+	StateTableName string `envconfig:"STATE_TABLE_NAME"`
+
+	// Only used while rotating the signing secret, the expiry is in JST unless it has a time zone
+	SlackPreviousSigningSecret       string `envconfig:"SLACK_PREVIOUS_SIGNING_SECRET"`
+	SlackPreviousSigningSecretExpiry string `envconfig:"SLACK_PREVIOUS_SIGNING_SECRET_EXPIRY"`
+	// Requests older than this number of seconds are rejected as replays
+	SlackSignatureMaxAge int `envconfig:"SLACK_SIGNATURE_MAX_AGE" default:"300"`
+
+	// Only used without the deadline of AWS Lambda, such as in the HTTP server or Socket Mode
+	CommandTimeout time.Duration `envconfig:"COMMAND_TIMEOUT" default:"14m"`
+	// Each call to slack or AWS, except uploading and downloading the files
+	CallTimeout time.Duration `envconfig:"CALL_TIMEOUT" default:"30s"`
+}
+
+func loadEnvConfig() (*envConfig, error) {
+	// Load environment variables
+	env := &envConfig{}
+
+	err := config.Load(env)
+	if err != nil {
+		return env, err
+	}
+
+	// Keep information obtained from environment variables in global variables.
+	envconf = env
+
+	return env, err
+}
@@ -0,0 +1,285 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// In-memory implementations of the AWS interfaces in services.go.
+// They do not access the network, so commands can be run in tests or locally without AWS.
+// The slack interfaces are faked in fakes_test.go.
+
+type memoryFileStore struct {
+	mu sync.Mutex
+	// "bucket/key" to the content of the object
+	objects map[string][]byte
+	now     func() time.Time
+}
+
+func newMemoryFileStore() *memoryFileStore {
+	s := &memoryFileStore{}
+	s.objects = make(map[string][]byte)
+	s.now = time.Now
+
+	return s
+}
+
+func (s *memoryFileStore) uploadAndPreSignedURL(ctx context.Context, bucket string, key string, body []byte, min int) (*storage.S3Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[bucket+"/"+key] = body
+
+	result := &storage.S3Item{}
+	result.Bucket = bucket
+	result.Key = key
+	result.ObjectExpiry, _ = datetime.DisplayDateString(strconv.FormatInt(s.now().AddDate(0, 0, 1).Unix(), 10), "")
+	result.PreSignedURL = fmt.Sprintf("https://%s.s3.amazonaws.com/%s?X-Amz-Expires=%d", bucket, key, min*60)
+	result.URLExpiry, _ = datetime.DisplayDateString(strconv.FormatInt(s.now().Unix(), 10), strconv.Itoa(min*60))
+
+	return result, nil
+}
+
+type memoryURLStore struct {
+	mu    sync.Mutex
+	items map[string]*storage.URLItem
+	now   func() time.Time
+}
+
+func newMemoryURLStore() *memoryURLStore {
+	s := &memoryURLStore{}
+	s.items = make(map[string]*storage.URLItem)
+	s.now = time.Now
+
+	return s
+}
+
+func (s *memoryURLStore) putURLItem(ctx context.Context, tableName string, id string, urlStr string, days int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	ttl := now.AddDate(0, 0, days).Unix()
+	s.items[tableName+"/"+id] = &storage.URLItem{ID: id, URL: urlStr, TTL: ttl, Time: now}
+
+	return ttl, nil
+}
+
+func (s *memoryURLStore) getURLItem(ctx context.Context, tableName string, id string) (*storage.URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[tableName+"/"+id]
+	if !ok {
+		return &storage.URLItem{}, errors.New("url item not found: " + id)
+	}
+
+	return item, nil
+}
+
+type memoryIdempotencyStore struct {
+	mu    sync.Mutex
+	items map[string]*storage.MutexItem
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	s := &memoryIdempotencyStore{}
+	s.items = make(map[string]*storage.MutexItem)
+
+	return s
+}
+
+func (s *memoryIdempotencyStore) acquireMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[tableName+"/"+id]; ok {
+		copied := *item
+		return &copied, false, nil
+	}
+	item := &storage.MutexItem{ID: id, Status: storage.MutexRunning, Time: time.Now()}
+	s.items[tableName+"/"+id] = item
+	copied := *item
+
+	return &copied, true, nil
+}
+
+func (s *memoryIdempotencyStore) restartMutexItem(ctx context.Context, tableName string, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[tableName+"/"+id]
+	if !ok || item.Status != storage.MutexFailed {
+		return false, nil
+	}
+	item.Status = storage.MutexRunning
+
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) completeMutexItem(ctx context.Context, tableName string, id string, status string, result string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[tableName+"/"+id]
+	if !ok {
+		return errors.New("mutex item not found: " + id)
+	}
+	item.Status = status
+	item.Result = result
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) getMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[tableName+"/"+id]
+	if !ok {
+		return &storage.MutexItem{}, errors.New("mutex item not found: " + id)
+	}
+	copied := *item
+
+	return &copied, nil
+}
+
+type memoryStateStore struct {
+	mu    sync.Mutex
+	items map[string]*storage.StateItem
+}
+
+func newMemoryStateStore() *memoryStateStore {
+	s := &memoryStateStore{}
+	s.items = make(map[string]*storage.StateItem)
+
+	return s
+}
+
+func (s *memoryStateStore) getStateItem(ctx context.Context, tableName string, id string) (*storage.StateItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[tableName+"/"+id]
+	if !ok {
+		return &storage.StateItem{ID: id}, nil
+	}
+	copied := *item
+
+	return &copied, nil
+}
+
+func (s *memoryStateStore) putStateItem(ctx context.Context, tableName string, item *storage.StateItem, days int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := int64(0)
+	if stored, ok := s.items[tableName+"/"+item.ID]; ok {
+		current = stored.Version
+	}
+	if current != item.Version {
+		return storage.ErrStateConflict
+	}
+
+	now := time.Now()
+	item.Version++
+	item.Time = now
+	item.TTL = now.AddDate(0, 0, days).Unix()
+	copied := *item
+	s.items[tableName+"/"+item.ID] = &copied
+
+	return nil
+}
+
+// Translates the text into "[target] text"
+type fakeTranslator struct {
+	err error
+	// Takes this long to translate, or until the context is done
+	delay time.Duration
+}
+
+func (f *fakeTranslator) translate(ctx context.Context, text string, source string, target string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	return "[" + target + "] " + text, nil
+}
+
+// Detects the language registered for the text, or the default code
+type fakeLanguageDetector struct {
+	codes       map[string]string
+	defaultCode string
+	err         error
+}
+
+func (f *fakeLanguageDetector) detectLanguageCode(ctx context.Context, text string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if code, ok := f.codes[text]; ok {
+		return code, nil
+	}
+
+	return f.defaultCode, nil
+}
+
+// Records the payloads instead of invoking the function
+type fakeInvoker struct {
+	mu       sync.Mutex
+	payloads map[string][][]byte
+}
+
+func newFakeInvoker() *fakeInvoker {
+	f := &fakeInvoker{}
+	f.payloads = make(map[string][][]byte)
+
+	return f
+}
+
+func (f *fakeInvoker) invokeAsync(ctx context.Context, functionName string, payload []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.payloads[functionName] = append(f.payloads[functionName], payload)
+
+	return nil
+}
+
+// All the AWS services in memory
+type memoryAWS struct {
+	*memoryFileStore
+	*memoryURLStore
+	*memoryIdempotencyStore
+	*memoryStateStore
+	*fakeTranslator
+	*fakeLanguageDetector
+	*fakeInvoker
+}
+
+func newMemoryAWS() *memoryAWS {
+	m := &memoryAWS{}
+	m.memoryFileStore = newMemoryFileStore()
+	m.memoryURLStore = newMemoryURLStore()
+	m.memoryIdempotencyStore = newMemoryIdempotencyStore()
+	m.memoryStateStore = newMemoryStateStore()
+	m.fakeTranslator = &fakeTranslator{}
+	m.fakeLanguageDetector = &fakeLanguageDetector{codes: make(map[string]string), defaultCode: "en"}
+	m.fakeInvoker = newFakeInvoker()
+
+	return m
+}
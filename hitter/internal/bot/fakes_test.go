@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// In-memory implementations of the slack interfaces in services.go, for the commands run without the fake server
+
+// A message posted with fakeChat.
+// The message options are decoded into the form values sent to the slack API, such as "blocks".
+type fakeMessage struct {
+	channel string
+	ts      string
+	values  url.Values
+}
+
+type fakeUpload struct {
+	channel  string
+	ts       string
+	filename string
+	comment  string
+	body     []byte
+}
+
+type fakeChat struct {
+	mu       sync.Mutex
+	messages []*fakeMessage
+	uploads  []*fakeUpload
+	// The users of each opened conversation
+	conversations map[string][]string
+	// Download URL to the content of the file
+	files map[string][]byte
+	// Returned from every call if set
+	err error
+}
+
+func newFakeChat() *fakeChat {
+	f := &fakeChat{}
+	f.files = make(map[string][]byte)
+	f.conversations = make(map[string][]string)
+
+	return f
+}
+
+func (f *fakeChat) openConversation(ctx context.Context, users ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return "", f.err
+	}
+	id := "G" + strings.Join(users, "")
+	f.conversations[id] = append([]string{}, users...)
+
+	return id, nil
+}
+
+func (f *fakeChat) postMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return "", "", f.err
+	}
+	_, values, err := slack.UnsafeApplyMsgOptions("", channel, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Timestamps are unique within the channel
+	ts := fmt.Sprintf("%d.%06d", time.Now().Unix(), len(f.messages)+1)
+	f.messages = append(f.messages, &fakeMessage{channel: channel, ts: ts, values: values})
+
+	return channel, ts, nil
+}
+
+func (f *fakeChat) uploadFile(ctx context.Context, channel string, body []byte, filename string, comment string, ts string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.uploads = append(f.uploads, &fakeUpload{channel: channel, ts: ts, filename: filename, comment: comment, body: body})
+
+	return nil
+}
+
+func (f *fakeChat) downloadFile(ctx context.Context, url string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, ok := f.files[url]
+	if !ok {
+		return nil, errors.New("file not found: " + url)
+	}
+
+	return body, nil
+}
+
+type fakeMembers struct {
+	// Channel ID to the user IDs in the channel
+	channels map[string][]string
+	bots     map[string]bool
+	err      error
+}
+
+func newFakeMembers() *fakeMembers {
+	f := &fakeMembers{}
+	f.channels = make(map[string][]string)
+	f.bots = make(map[string]bool)
+
+	return f
+}
+
+func (f *fakeMembers) getUsers(ctx context.Context, channelID string, limit int) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return append([]string{}, f.channels[channelID]...), nil
+}
+
+func (f *fakeMembers) classifyUsers(ctx context.Context, ids ...string) ([]string, []string, error) {
+	var botIds []string
+	var userIds []string
+	if f.err != nil {
+		return userIds, botIds, f.err
+	}
+
+	for _, id := range ids {
+		if f.bots[id] {
+			botIds = append(botIds, id)
+		} else {
+			userIds = append(userIds, id)
+		}
+	}
+
+	return userIds, botIds, nil
+}
@@ -0,0 +1,134 @@
+// Package bot handles the requests from slack and runs the commands.
+// It is started from the bot Lambda function, the HTTP server or Socket Mode.
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// Returning the error will result in "message": "Internal server error" with 502 Bad Gateway, so do not return the error if you want a custom display.
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Load information from environment variables and make it available on a global basis
+	env, err := backends.env()
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("request: %+v\n", request)
+
+	// The body must be verified exactly as it was sent from slack
+	body := request.Body
+	if request.IsBase64Encoded {
+		b, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 400}, nil
+		}
+		body = string(b)
+	}
+
+	// Verify the request signature with the slack signing secret
+	err = newSignatureVerifier(ctx, env).verify(ctx, request.Headers, body)
+	if err != nil {
+		logger(ctx).Println("[REJECTED] The slack signature could not be verified: ", err)
+		result := `{"message": "[REJECTED] The slack signature could not be verified"}`
+		return events.APIGatewayProxyResponse{Body: result, StatusCode: 401}, nil
+	}
+
+	// Slash commands are sent in a different format from the events
+	if isSlashCommandRequest(request.Headers) {
+		return handleSlashCommand(ctx, env, body)
+	}
+
+	return handleEvent(ctx, env, body, parseRetry(ctx, request.Headers))
+}
+
+func handleEvent(ctx context.Context, env *envConfig, body string, retry *retryInfo) (events.APIGatewayProxyResponse, error) {
+	// Initialize the slack client
+	sc := backends.slack(env)
+
+	// Parsing JSON of events sent from slack
+	se, result, err := sc.parseEvent(ctx, body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+	if result != "" {
+		return events.APIGatewayProxyResponse{Body: result, StatusCode: 200}, nil
+	}
+	ctx = logging.NewContext(ctx, se.logFields())
+
+	// Initialize the aws client
+	aws, err := backends.aws(env)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	// Do not process the same event multiple times, retried events are handled according to the reason
+	item, acquired, err := aws.acquireMutexItem(ctx, env.MutexTableName, se.EventID)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+	if !acquired && retry != nil && retry.action == rerunRetry && item.Status == storage.MutexFailed {
+		// Process the event again only if the previous attempt failed
+		acquired, err = aws.restartMutexItem(ctx, env.MutexTableName, se.EventID)
+		if err != nil {
+			return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+		}
+	}
+	if !acquired {
+		logger(ctx).Println("[REJECTED] Already running under the same slack event ID: ", item.ID, item.Status)
+		if item.Status == storage.MutexRunning {
+			result = `{"message": "[REJECTED] Already running under the same slack event ID"}`
+			return events.APIGatewayProxyResponse{Headers: noRetryHeaders(), Body: result, StatusCode: 200}, nil
+		}
+
+		// Answer with the stored result of the same event
+		return events.APIGatewayProxyResponse{Headers: noRetryHeaders(), Body: item.Result, StatusCode: 200}, nil
+	}
+
+	// Leave the execution of the command to the worker and respond to slack immediately
+	queue, err := getJobQueue(ctx, env, aws)
+	if err == nil {
+		err = queue.enqueue(ctx, &commandJob{Kind: eventJob, EventID: se.EventID, Body: body})
+	}
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to enqueue the command: ", err)
+		b, _ := json.Marshal(map[string]string{"result": "failed", "message": err.Error()})
+		aws.completeMutexItem(ctx, env.MutexTableName, se.EventID, storage.MutexFailed, string(b))
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+	result = `{"result": "accepted"}`
+
+	return events.APIGatewayProxyResponse{Body: result, StatusCode: 200}, nil
+}
+
+// HandleInvocation is the handler of the bot Lambda function.
+// It receives both API Gateway requests and its own asynchronous invocations.
+func HandleInvocation(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	// Every line of the log carries the request ID of the invocation
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		ctx = logging.NewContext(ctx, logging.Fields{"request_id": lc.AwsRequestID})
+	}
+
+	envelope := &jobEnvelope{}
+	if err := json.Unmarshal(payload, envelope); err == nil && envelope.Job != nil {
+		// Do not return the error, or Lambda will retry the command
+		runJob(ctx, envelope.Job)
+		return nil, nil
+	}
+
+	request := events.APIGatewayProxyRequest{}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		logger(ctx).Println("[ERROR] Failed to parse the request: ", err)
+		return nil, err
+	}
+
+	return handleRequest(ctx, request)
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// The help text is generated from the command declarations,
+// so it is always the same as what the parser accepts.
+
+const botName = "@hitter"
+
+func (k valueKind) String() string {
+	switch k {
+	case intValue:
+		return "Number"
+	case urlValue:
+		return "URL"
+	case userValue:
+		return "User"
+	case boolValue:
+		return "Boolean"
+	case usergroupValue:
+		return "UserGroup"
+	case channelValue:
+		return "Channel"
+	default:
+		return "Text"
+	}
+}
+
+func createSynopsis(spec *commandSpec) string {
+	text := botName + " " + spec.name
+	for _, a := range spec.arguments {
+		v := "<" + a.name + ">"
+		if a.rest {
+			v = "<" + a.name + " ...>"
+		}
+		if !a.required {
+			v = "[" + v + "]"
+		}
+		text = text + " " + v
+	}
+	for _, o := range spec.options {
+		v := o.name + " <" + o.kind.String() + ">"
+		if o.kind == boolValue {
+			v = o.name
+		}
+		if o.repeatable {
+			v = v + " ..."
+		}
+		text = text + " [" + v + "]"
+	}
+
+	return text
+}
+
+func createConstraints(defaultValue string, min int, max int, repeatable bool) string {
+	var items []string
+	if defaultValue != "" {
+		items = append(items, "default: "+defaultValue)
+	}
+	if min != 0 {
+		items = append(items, "min: "+strconv.Itoa(min))
+	}
+	if max != 0 {
+		items = append(items, "max: "+strconv.Itoa(max))
+	}
+	if repeatable {
+		items = append(items, "repeatable")
+	}
+	if len(items) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(items, ", ") + ")"
+}
+
+func createHelpIndex() string {
+	text := ""
+	for _, spec := range registry.list() {
+		text = text + ":book: *" + spec.name + "*  " + spec.description + "\n"
+		text = text + "`" + createSynopsis(spec) + "`\n"
+	}
+
+	text = text + "\n*Options for all commands:*\n"
+	for _, o := range globalOptions {
+		text = text + "`" + o.name + "`  " + o.description + "\n"
+	}
+
+	return "*Commands:*\n" + text + "\n> :information_source: _Use `" + botName + " help <command>` for the details of each command._"
+}
+
+func createCommandHelp(spec *commandSpec) string {
+	text := ":book: *" + spec.name + "*\n"
+	text = text + "```"
+	text = text + "DESCRIPTION: \n"
+	text = text + " • " + spec.description + "\n"
+	for _, n := range spec.notes {
+		text = text + " • " + n + "\n"
+	}
+	if len(spec.aliases) > 0 {
+		text = text + "ALIASES: \n"
+		text = text + " • " + strings.Join(spec.aliases, ", ") + "\n"
+	}
+	text = text + "SYNOPSIS: \n"
+	text = text + " • " + createSynopsis(spec) + "\n"
+	if len(spec.arguments) > 0 {
+		text = text + "ARGUMENTS: \n"
+		for _, a := range spec.arguments {
+			text = text + " • <" + a.name + "> " + a.description + createConstraints(a.defaultValue, a.min, a.max, false) + "\n"
+		}
+	}
+	if len(spec.options) > 0 {
+		text = text + "OPTIONS: \n"
+		for _, o := range spec.options {
+			v := o.name + " <" + o.kind.String() + ">"
+			if o.kind == boolValue {
+				v = o.name
+			}
+			text = text + " • " + v + " " + o.description + createConstraints(o.defaultValue, o.min, o.max, o.repeatable) + "\n"
+		}
+	}
+	if len(spec.examples) > 0 {
+		text = text + "EXAMPLES: \n"
+		for _, e := range spec.examples {
+			text = text + " • " + botName + " " + e + "\n"
+		}
+	}
+	text = text + "```\n"
+
+	return "*Command:*\n" + text + "\n> :information_source: _See the documentation if you need more details._"
+}
+
+func createUnknownCommandHelp(ctx context.Context, name string) string {
+	text := ":question: Unknown command: *" + name + "*\n"
+	if s := suggestCommand(ctx, name); s != "" {
+		text = text + "Did you mean *" + s + "*?\n"
+	}
+
+	return text + "\n" + createHelpIndex()
+}
+
+func suggestCommand(ctx context.Context, name string) string {
+	// Suggest the closest command name or alias within a small distance
+	best := ""
+	bestDist := 3
+	for _, spec := range registry.list() {
+		for _, n := range append([]string{spec.name}, spec.aliases...) {
+			d := levenshtein(strings.ToLower(name), n)
+			if d < bestDist || (best == "" && name != "" && strings.HasPrefix(n, strings.ToLower(name))) {
+				best = spec.name
+				bestDist = d
+			}
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("suggestion: %+v -> %+v\n", name, best)
+
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, minInt(cur[j-1]+1, prev[j-1]+cost))
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
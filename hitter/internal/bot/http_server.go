@@ -0,0 +1,27 @@
+package bot
+
+import "github.com/uchimanajet7/hitter/hitter/internal/httpproxy"
+
+// The HTTP server mode runs the same handler as API Gateway without AWS Lambda,
+// so the bot can run on-prem and the whole flow can be tested locally.
+
+// RunHTTPServer serves the bot on LISTEN_ADDR until SIGINT or SIGTERM is received.
+func RunHTTPServer() error {
+	// Load information from environment variables and make it available on a global basis
+	env, err := backends.env()
+	if err != nil {
+		return err
+	}
+
+	err = httpproxy.ListenAndServe(env.ListenAddr, handleRequest)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the running commands to finish
+	if sharedLocalQueue != nil {
+		sharedLocalQueue.close()
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/httpproxy"
+)
+
+// The HTTP server mode verifies and accepts the event in the same way as API Gateway
+func TestHTTPServerEvent(t *testing.T) {
+	const event = `{"type":"event_callback","event_id":"Ev0001","event":{"type":"app_mention","text":"<@UHITTERBOT> hit","user":"U0001","channel":"C0123","ts":"1595673533.002100","event_ts":"1595673533.002100"}}`
+
+	env, aws := setupRetryTest(t)
+	env.SlackSigningSecret = "secret"
+	env.SlackSignatureMaxAge = 300
+
+	server := httptest.NewServer(httpproxy.NewHandler(handleRequest))
+	defer server.Close()
+
+	send := func(secret string) (int, string) {
+		req, err := http.NewRequest("POST", server.URL+"/slack/events", strings.NewReader(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range signRequest(secret, time.Now().Unix(), event) {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	if status, body := send("other"); status != 401 {
+		t.Errorf("got %d %s with the wrong secret, want 401", status, body)
+	}
+	if len(aws.payloads[env.FunctionName]) != 0 {
+		t.Error("the unsigned event is enqueued")
+	}
+
+	if status, body := send("secret"); status != 200 || !strings.Contains(body, "accepted") {
+		t.Errorf("got %d %s, want 200 accepted", status, body)
+	}
+	if len(aws.payloads[env.FunctionName]) != 1 {
+		t.Errorf("enqueued %d events, want 1", len(aws.payloads[env.FunctionName]))
+	}
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// Message shortcuts run a command with the text of the message.
+// The callback ID of the shortcut is the command name, such as "translate" or "link".
+// https://api.slack.com/interactivity/shortcuts/using#message_shortcuts
+
+func parseInteraction(ctx context.Context, body string) (*slack.InteractionCallback, error) {
+	// Output debug log
+	logger(ctx).Debugf("body: %+v\n", body)
+
+	callback := &slack.InteractionCallback{}
+	err := json.Unmarshal([]byte(body), callback)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to parse the interaction.: ", err)
+	}
+
+	return callback, err
+}
+
+func handleInteraction(ctx context.Context, env *envConfig, callback *slack.InteractionCallback, body string) (events.APIGatewayProxyResponse, error) {
+	ctx = logging.NewContext(ctx, logging.Fields{
+		"team":    callback.Team.ID,
+		"channel": callback.Channel.ID,
+		"user":    callback.User.ID,
+		"command": callback.CallbackID,
+	})
+
+	if callback.Type != slack.InteractionTypeMessageAction {
+		logger(ctx).Println("[REJECTED] The interaction type is not supported: ", callback.Type)
+		result := `{"message": "[REJECTED] The interaction type is not supported"}`
+		return events.APIGatewayProxyResponse{Body: result, StatusCode: 200}, nil
+	}
+
+	// filter the channel?
+	if env.SlackChannelID != "" && env.SlackChannelID != callback.Channel.ID {
+		logger(ctx).Println("[REJECTED] Slack channel ID do not match: ", callback.Channel.ID)
+		result := `{"message": "[REJECTED] Slack channel ID do not match"}`
+		return events.APIGatewayProxyResponse{Body: result, StatusCode: 200}, nil
+	}
+
+	// Initialize the aws client
+	aws, err := backends.aws(env)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	// Interactions are not retried either, so there is no need for the mutex table
+	queue, err := getJobQueue(ctx, env, aws)
+	if err == nil {
+		err = queue.enqueue(ctx, &commandJob{Kind: interactiveJob, Body: body})
+	}
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to enqueue the command: ", err)
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	return events.APIGatewayProxyResponse{Body: "", StatusCode: 200}, nil
+}
+
+func parseInteractionParameter(ctx context.Context, callback *slack.InteractionCallback, defaultResponseType string) *commandParameter {
+	cmdParam := &commandParameter{}
+	cmdParam.team = callback.Team.ID
+	cmdParam.channel = callback.Channel.ID
+	cmdParam.eventTs = callback.ActionTs
+	cmdParam.from = callback.User.ID
+	cmdParam.text = callback.Message.Text
+	cmdParam.arguments = make(map[string]string)
+	cmdParam.options = make(map[string][]string)
+	cmdParam.files = make(map[string]string)
+	cmdParam.responseURL = callback.ResponseURL
+	cmdParam.responseType = defaultResponseType
+
+	// The attachments of the message are the target of the command
+	for _, f := range callback.Message.Files {
+		cmdParam.files[f.URLPrivateDownload] = f.Name
+	}
+
+	// The message text is only passed to commands that take the rest of the text, such as translate.
+	// Other commands run with the default values.
+	cmdParam.command = callback.CallbackID
+	if spec, ok := registry.lookup(cmdParam.command); ok {
+		if len(spec.arguments) > 0 && spec.arguments[0].rest {
+			cmdParam.body = " -- " + callback.Message.Text
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("cmdParam: %+v\n", cmdParam)
+
+	return cmdParam
+}
@@ -0,0 +1,335 @@
+package bot
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// The messages are built separately from posting them,
+// so the Block Kit payloads can be compared with the golden files in testdata.
+
+type stateEnum int
+
+const (
+	successState stateEnum = iota
+	failureState
+	helpState
+)
+
+// A Block Kit message and the result file attached to its thread
+type notification struct {
+	Blocks []slack.Block `json:"blocks"`
+	File   *resultFile   `json:"file,omitempty"`
+}
+
+type resultFile struct {
+	// The posted date is added to the beginning of the name
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Comment string `json:"comment"`
+}
+
+func createSummarySection(mention string, state stateEnum) *slack.SectionBlock {
+	// Mentions to the commander and results summary section
+	var text string
+
+	switch state {
+	case successState:
+		text = ":confetti_ball: I successfully executed the requested command."
+	case failureState:
+		text = ":rotating_light: I failed to execute the requested command."
+	case helpState:
+		text = ":thinking_face: Please check the following command help."
+	default:
+		// unidentified
+		text = ":eyes: An exempt designation has been made."
+	}
+
+	if mention != "" {
+		// Adding Mentions to a Target
+		text = "<@" + mention + "> \n" + text
+	}
+
+	summaryText := slack.NewTextBlockObject("mrkdwn", text, false, false)
+	summarySection := slack.NewSectionBlock(summaryText, nil, nil)
+
+	return summarySection
+}
+
+func createInfoSection(cmd string, eventTs string) *slack.SectionBlock {
+	// Input information Section
+	// ex.)1595673533.002100
+	dispDate, _ := datetime.DisplayDateString(strings.Replace(eventTs, ".", "", -1), "")
+
+	// They say the maximum character count is about 4,000 characters.
+	// https://www.cotegg.com/blog/?p=1951#result
+	// The input and output strings are 4,000 characters in total, so we'll omit them in about half.
+	text := cmd
+	if utf8.RuneCountInString(text) > 2000 {
+		r := []rune(text)
+		text = string(r[:1950]) + "...(omitted)"
+	}
+
+	text = "*Command:*\n```" + text + "```\n:clock8: " + dispDate
+	infoText := slack.NewTextBlockObject("mrkdwn", text, false, false)
+	infoSection := slack.NewSectionBlock(infoText, nil, nil)
+
+	return infoSection
+}
+
+func createResultSection(text string) *slack.SectionBlock {
+	resultText := slack.NewTextBlockObject("mrkdwn", text, false, false)
+
+	return slack.NewSectionBlock(resultText, nil, nil)
+}
+
+// Slack rejects a section whose text is longer than this
+const maxSectionTextLength = 3000
+
+// The list of all members in a large channel does not fit in one section,
+// so the text is split into sections at the line breaks.
+func createResultSections(text string) []*slack.SectionBlock {
+	var sections []*slack.SectionBlock
+	for text != "" {
+		chunk := text
+		if r := []rune(text); len(r) > maxSectionTextLength {
+			chunk = string(r[:maxSectionTextLength])
+			// A line longer than a section, such as a large team, is split between the mentions
+			if i := strings.LastIndex(chunk, "\n"); i > 0 {
+				chunk = chunk[:i]
+			} else if i := strings.LastIndex(chunk, " "); i > 0 {
+				chunk = chunk[:i]
+			}
+		}
+		text = strings.TrimLeft(text[len(chunk):], "\n ")
+		sections = append(sections, createResultSection(strings.TrimRight(chunk, "\n ")))
+	}
+
+	return sections
+}
+
+// Summary, input information and result, separated by dividing lines
+func createResultBlocks(cp *commandParameter, state stateEnum, info *slack.SectionBlock, result ...*slack.SectionBlock) []slack.Block {
+	// dividing line section
+	divSection := slack.NewDividerBlock()
+
+	blocks := []slack.Block{
+		createSummarySection(cp.from, state),
+		divSection,
+		info,
+		divSection,
+	}
+	for _, v := range result {
+		blocks = append(blocks, v)
+	}
+
+	return append(blocks, divSection)
+}
+
+func buildErrorMessage(cp *commandParameter, message string) *notification {
+	// Command Execution Error Result Section
+	text := "*Results:*\n:name_badge: " + message + "\n> :warning: _Be sure to check the help if you want to rerun the command._"
+
+	return &notification{Blocks: createResultBlocks(cp, failureState, createInfoSection(cp.text, cp.eventTs), createResultSection(text))}
+}
+
+func buildHelpMessage(cp *commandParameter, text string) *notification {
+	// dividing line section
+	divSection := slack.NewDividerBlock()
+
+	// Help Details generated from the command declarations
+	blocks := []slack.Block{
+		createSummarySection(cp.from, helpState),
+		divSection,
+		createResultSection(text),
+		divSection,
+	}
+
+	return &notification{Blocks: blocks}
+}
+
+func buildHitMessage(cp *commandParameter, result []string) *notification {
+	// Command Execution Result Section
+	text := ""
+	for i, v := range result {
+		num := strconv.Itoa(i + 1)
+		text = text + ":tada: *[" + num + "]:*  <@" + v + "> You are the *" + num + "th* choice.\n\n"
+	}
+	text = "*Results:*\n" + text + "\n> :zap: _If you have a problem with your choice, please try again._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSections(text)...)}
+}
+
+func buildTeamsMessage(cp *commandParameter, teams [][]string) *notification {
+	// Command Execution Result Section
+	text := ""
+	for i, team := range teams {
+		text = text + ":busts_in_silhouette: *Team " + strconv.Itoa(i+1) + ":*  " + mentionList(team) + "\n\n"
+	}
+	text = "*Results:*\n" + text + "\n> :zap: _If you have a problem with the teams, please try again._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSections(text)...)}
+}
+
+func mentionList(users []string) string {
+	mentions := []string{}
+	for _, v := range users {
+		mentions = append(mentions, "<@"+v+">")
+	}
+
+	return strings.Join(mentions, " ")
+}
+
+// The failed groups are those whose introduction could not be posted
+func buildPairMessage(cp *commandParameter, groups [][]string, repeated int, failed [][]string) *notification {
+	// Command Execution Result Section
+	text := ""
+	for _, g := range groups {
+		text = text + ":coffee: " + mentionList(g) + "\n\n"
+	}
+	if repeated > 0 {
+		text = text + ":repeat: _" + strconv.Itoa(repeated) + " of the pairs met recently, as there was no other way to pair the members._\n\n"
+	}
+	for _, g := range failed {
+		text = text + ":warning: _The introduction could not be sent to " + mentionList(g) + ", please say hello yourselves._\n\n"
+	}
+	text = "*Results:*\n" + text + "\n> :zap: _Please find a time that works for both of you._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSections(text)...)}
+}
+
+// Posted to the group DM of each pair
+func buildPairIntroMessage(cp *commandParameter, group []string) *notification {
+	text := ":wave: Hello " + mentionList(group) + "!\n"
+	text = text + "You have been paired for a chat by <@" + cp.from + "> in <#" + cp.channel + ">.\n"
+	text = text + "Please find a time that works for all of you."
+
+	return &notification{Blocks: []slack.Block{createResultSection(text)}}
+}
+
+// The starts have the end time of the last member at the end, if there are any
+func buildOrderMessage(cp *commandParameter, users []string, starts []string) *notification {
+	// Command Execution Result Section
+	text := ""
+	for i, v := range users {
+		text = text + "*[" + strconv.Itoa(i+1) + "]:*  <@" + v + ">"
+		if len(starts) > i {
+			text = text + "  :clock930: " + starts[i]
+		}
+		text = text + "\n"
+	}
+	if len(starts) > len(users) {
+		text = text + "\n:checkered_flag: Ends at " + starts[len(users)] + "\n"
+	}
+	text = "*Results:*\n" + text + "\n> :zap: _If you have a problem with the order, please try again._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSections(text)...)}
+}
+
+func buildRotationMessage(cp *commandParameter, name string, order []string, next int) *notification {
+	// Command Execution Result Section
+	text := ""
+	for i, v := range order {
+		mark := ":white_small_square:"
+		if i == next {
+			mark = ":arrow_forward:"
+		}
+		text = text + mark + " *[" + strconv.Itoa(i+1) + "]:*  <@" + v + ">\n"
+	}
+	if text == "" {
+		text = ":ghost: There are no members in the rotation.\n"
+	}
+	text = "*Results:*\n:arrows_counterclockwise: Rotation *" + name + "*\n\n" + text + "\n> :zap: _The next member is selected with `hit --rotate " + name + "`._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSections(text)...)}
+}
+
+func buildTranslateMessage(cp *commandParameter, source string, translated string, sourceLangCode string, translatedLangCode string) *notification {
+	// Command Execution Result Section
+	text := "*Results:*\n:dart: Translated the text from *[" + sourceLangCode + "]* to *[" + translatedLangCode + "]*\n\n`Please check the file attached to the thread for details of the translation command results.`\n\n> :zap: _If there is a problem with the translation, please check the input text and try again._"
+
+	// Organize the output to a file
+	body := "• Source text: [" + sourceLangCode + "]\n\n"
+	body = body + source + "\n\n\n\n"
+	body = body + "• Translated text: [" + translatedLangCode + "]\n\n"
+	body = body + translated + "\n"
+
+	file := &resultFile{}
+	file.Name = "translate_command_result.text"
+	file.Body = body
+	file.Comment = ":dart: This file is the result of the translation command.\n"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSection(text)), File: file}
+}
+
+func buildLinkMessage(cp *commandParameter, results []*storage.S3Item) *notification {
+	// Get input information Section
+	values := []string{}
+	for _, v := range cp.files {
+		values = append(values, v)
+	}
+	// Map order is random, so sort the names to keep the message stable
+	sort.Strings(values)
+	info := createInfoSection(cp.text+" <"+strings.Join(values, ", ")+">", cp.eventTs)
+
+	// Command Execution Result Section
+	text := ""
+	if len(results) > 1 {
+		text = strconv.Itoa(len(results)) + " files "
+	}
+	text = "*Results:*\n:linked_paperclips: " + text + "S3 Object information and Pre-Signed URL\n\n`Please check the file attached to the thread for details of the link command results.`\n\n> :satellite_antenna: _If you want to change the expiry date, please try again._"
+
+	// Organize the output to a file
+	body := ""
+	for i, v := range results {
+		num := strconv.Itoa(i + 1)
+		body = body + "• [" + num + "]: S3 Object information and Pre-Signed URL\n\n\n"
+		body = body + "S3 Object: \n"
+		body = body + " • bucket: \n"
+		body = body + "      " + v.Bucket + "\n\n"
+		body = body + " • key: \n"
+		body = body + "      " + v.Key + "\n\n"
+		body = body + " • expiry date: \n"
+		body = body + "      " + v.ObjectExpiry + "\n\n\n"
+		body = body + "Pre-Signed URL: \n"
+		body = body + " • URL: \n"
+		body = body + "      " + v.PreSignedURL + "\n\n"
+		body = body + " • expiry date: \n"
+		body = body + "      " + v.URLExpiry + "\n\n\n\n"
+	}
+
+	file := &resultFile{}
+	file.Name = "link_command_result.text"
+	file.Body = body
+	file.Comment = ":linked_paperclips: This file is the result of the link command.\n"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, info, createResultSection(text)), File: file}
+}
+
+func buildShortMessage(cp *commandParameter, urlStr string, dateStr string) *notification {
+	// Command Execution Result Section
+	text := ":link: " + urlStr + "\n\n"
+	text = text + ":clock930: " + dateStr + "\n"
+	text = "*Results:*\n" + text + "\n> :globe_with_meridians: _If you want to change the expiry date, please try again._"
+
+	return &notification{Blocks: createResultBlocks(cp, successState, createInfoSection(cp.text, cp.eventTs), createResultSection(text))}
+}
+
+// Files cannot be attached to responses of slash commands or ephemeral messages,
+// so the result is returned as a message instead.
+func buildResultFileMessage(file *resultFile) *notification {
+	text := file.Body
+	if utf8.RuneCountInString(text) > 2900 {
+		r := []rune(text)
+		text = string(r[:2850]) + "...(omitted)"
+	}
+	text = file.Comment + "```" + text + "```"
+
+	return &notification{Blocks: []slack.Block{createResultSection(text)}}
+}
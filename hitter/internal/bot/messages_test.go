@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// Run "go test -run TestGolden -update" to rewrite the golden files after changing a message
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func newGoldenCommand(text string) *commandParameter {
+	c := &commandParameter{}
+	c.channel = "C0123"
+	c.eventTs = "1595673533.002100"
+	c.from = "U0001"
+	c.text = "<@UHITTERBOT> " + text
+	c.command, c.body = splitHead(text)
+
+	return c
+}
+
+func TestGoldenMessages(t *testing.T) {
+	link := newGoldenCommand("link 30")
+	link.files = map[string]string{"https://files.slack.com/F0002": "b.pdf", "https://files.slack.com/F0001": "a.txt"}
+	linkResults := []*storage.S3Item{
+		{
+			Bucket:       "hitter-bucket",
+			Key:          "4b3ad6a3-53c2-4ab6-9a8f-7d5a4d52c5bb/a.txt",
+			ObjectExpiry: "2020/07/27 Mon 09:00:00 JST",
+			PreSignedURL: "https://hitter-bucket.s3.amazonaws.com/4b3ad6a3-53c2-4ab6-9a8f-7d5a4d52c5bb/a.txt?X-Amz-Expires=1800",
+			URLExpiry:    "2020/07/25 Sat 20:08:53 JST",
+		},
+		{
+			Bucket:       "hitter-bucket",
+			Key:          "4b3ad6a3-53c2-4ab6-9a8f-7d5a4d52c5bb/b.pdf",
+			ObjectExpiry: "2020/07/27 Mon 09:00:00 JST",
+			PreSignedURL: "https://hitter-bucket.s3.amazonaws.com/4b3ad6a3-53c2-4ab6-9a8f-7d5a4d52c5bb/b.pdf?X-Amz-Expires=1800",
+			URLExpiry:    "2020/07/25 Sat 20:08:53 JST",
+		},
+	}
+
+	hitSpec, _ := registry.lookup("hit")
+
+	tests := []struct {
+		name string
+		n    *notification
+	}{
+		{"error", buildErrorMessage(newGoldenCommand("hit 100"), "Command execution failed. *[There are too many choices: 100/3]*")},
+		{"error_pointer", buildErrorMessage(newGoldenCommand("hit 0"), "Command execution failed. *[Invalid value for <number>, must be at least 1: 0 (column 5)]*\n```hit 0\n    ^```")},
+		{"help_index", buildHelpMessage(newGoldenCommand("help"), createHelpIndex())},
+		{"help_hit", buildHelpMessage(newGoldenCommand("help hit"), createCommandHelp(hitSpec))},
+		{"help_unknown", buildHelpMessage(newGoldenCommand("hti"), createUnknownCommandHelp(context.Background(), "hti"))},
+		{"hit", buildHitMessage(newGoldenCommand("hit 2 --ex <@U0003>"), []string{"U0002", "U0004"})},
+		{"teams", buildTeamsMessage(newGoldenCommand("teams 2 --apart <@U0001> --apart <@U0002>"), [][]string{{"U0003", "U0001"}, {"U0002", "U0004", "U0005"}})},
+		{"pair", buildPairMessage(newGoldenCommand("pair --dm"), [][]string{{"U0001", "U0003"}, {"U0002", "U0004", "U0005"}}, 1, nil)},
+		{"pair_failed", buildPairMessage(newGoldenCommand("pair --dm"), [][]string{{"U0001", "U0003"}, {"U0002", "U0004"}}, 0, [][]string{{"U0002", "U0004"}})},
+		{"pair_intro", buildPairIntroMessage(newGoldenCommand("pair --dm"), []string{"U0001", "U0003"})},
+		{"order", buildOrderMessage(newGoldenCommand("order --slot 2m --start 09:30"), []string{"U0003", "U0001", "U0002"}, []string{"09:30", "09:32", "09:34", "09:36"})},
+		{"rotation", buildRotationMessage(newGoldenCommand("rotation show standup"), "standup", []string{"U0003", "U0001", "U0002"}, 1)},
+		{"translate", buildTranslateMessage(newGoldenCommand("translate hello world"), "hello world", "こんにちは世界", "en", "ja")},
+		{"link", buildLinkMessage(link, linkResults)},
+		{"short", buildShortMessage(newGoldenCommand("short <https://aws.amazon.com/jp/> --ttl 7"), "https://short.example.com/v1/1a2b3c4d", "2020/08/01 Sat 19:38:53 JST")},
+		{"result_file", buildResultFileMessage(&resultFile{Name: "translate_command_result.text", Body: "• Source text: [en]\n\nhello\n", Comment: ":dart: This file is the result of the translation command.\n"})},
+		{"result_file_omitted", buildResultFileMessage(&resultFile{Name: "translate_command_result.text", Body: strings.Repeat("0123456789", 300), Comment: ":dart: This file is the result of the translation command.\n"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Keep "<@U0001>" as is, so the diff is readable
+			var b bytes.Buffer
+			enc := json.NewEncoder(&b)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			err := enc.Encode(tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := b.Bytes()
+
+			golden := filepath.Join("testdata", "golden", tt.name+".json")
+			if *update {
+				err := ioutil.WriteFile(golden, got, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("the message differs from %s (run with -update if the change is intended)\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// Slack rejects the message if a section is longer than maxSectionTextLength,
+// even if the whole message would fit
+func TestLargeChannelMessages(t *testing.T) {
+	var users, starts []string
+	for i := 0; i < 70; i++ {
+		users = append(users, fmt.Sprintf("W%010d", i))
+		starts = append(starts, fmt.Sprintf("%02d:%02d", 9+i/60, i%60))
+	}
+	starts = append(starts, "10:10")
+	pairs := [][]string{}
+	for i := 0; i < len(users); i += 2 {
+		pairs = append(pairs, users[i:i+2])
+	}
+
+	tests := []struct {
+		name string
+		n    *notification
+	}{
+		{"hit", buildHitMessage(newGoldenCommand("hit 70"), users)},
+		{"teams", buildTeamsMessage(newGoldenCommand("teams 3"), [][]string{users, users, users})},
+		{"one team", buildTeamsMessage(newGoldenCommand("teams 1"), [][]string{append(append(users, users...), users...)})},
+		{"pair", buildPairMessage(newGoldenCommand("pair --dm"), pairs, 3, pairs)},
+		{"order", buildOrderMessage(newGoldenCommand("order --slot 1m --start 09:00"), users, starts)},
+		{"rotation", buildRotationMessage(newGoldenCommand("rotation show standup"), "standup", users, 69)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := ""
+			sections := 0
+			for _, b := range tt.n.Blocks {
+				s, ok := b.(*slack.SectionBlock)
+				if !ok {
+					continue
+				}
+				sections++
+				if n := utf8.RuneCountInString(s.Text.Text); n > maxSectionTextLength || n == 0 {
+					t.Errorf("section %d has %d characters", sections, n)
+				}
+				all += s.Text.Text + "\n"
+			}
+			// The summary, the input information and the result split into several sections
+			if sections < 4 {
+				t.Errorf("got %d sections, want the result split", sections)
+			}
+			for _, u := range users {
+				if !strings.Contains(all, "<@"+u+">") {
+					t.Errorf("%s is missing from the message", u)
+				}
+			}
+		})
+	}
+}
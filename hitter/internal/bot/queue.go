@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// Slack expects a response within 3 seconds, so the bot endpoint only accepts the event
+// and the command is executed by a worker.
+// - lambda: the bot Lambda function invokes itself asynchronously
+// - local: an in-process queue, used to run the whole flow without AWS
+// - inline: runs the command before responding, used to replay events
+const (
+	lambdaQueueMode = "lambda"
+	localQueueMode  = "local"
+	inlineQueueMode = "inline"
+)
+
+// The kind of the request body
+const (
+	eventJob        = "event"
+	slashCommandJob = "slash_command"
+	interactiveJob  = "interactive"
+)
+
+// The verified request to be executed by the worker
+type commandJob struct {
+	Kind    string `json:"kind"`
+	EventID string `json:"event_id"`
+	Body    string `json:"body"`
+}
+
+// Invocations of the worker are distinguished from API Gateway requests by this key
+type jobEnvelope struct {
+	Job *commandJob `json:"hitter_job"`
+}
+
+type jobQueue interface {
+	enqueue(ctx context.Context, job *commandJob) error
+}
+
+type lambdaQueue struct {
+	aws          functionInvoker
+	functionName string
+}
+
+func (q *lambdaQueue) enqueue(ctx context.Context, job *commandJob) error {
+	payload, err := json.Marshal(&jobEnvelope{Job: job})
+	if err != nil {
+		return err
+	}
+
+	return q.aws.invokeAsync(ctx, q.functionName, payload)
+}
+
+// Jobs waiting for a worker, more are rejected
+const localQueueSize = 100
+
+type localQueue struct {
+	jobs chan *commandJob
+	wg   sync.WaitGroup
+}
+
+func newLocalQueue(workers int, worker func(job *commandJob)) *localQueue {
+	q := &localQueue{}
+	q.jobs = make(chan *commandJob, localQueueSize)
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for job := range q.jobs {
+				worker(job)
+			}
+		}()
+	}
+
+	return q
+}
+
+// The request is answered within 3 seconds, so it does not wait for a free slot
+var errQueueFull = errors.New("Too many commands are waiting, please try again later")
+
+func (q *localQueue) enqueue(ctx context.Context, job *commandJob) error {
+	// Do not accept the job if the request has already been given up
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// The job outlives the request, so it does not take over the context
+	select {
+	case q.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return errQueueFull
+	}
+}
+
+// Stop accepting jobs and wait for the running jobs to finish
+func (q *localQueue) close() {
+	close(q.jobs)
+	q.wg.Wait()
+}
+
+type inlineQueue struct{}
+
+func (q *inlineQueue) enqueue(ctx context.Context, job *commandJob) error {
+	// The outcome is recorded in the mutex table in the same way as the other queues
+	runJob(ctx, job)
+
+	return nil
+}
+
+var (
+	sharedLocalQueue     *localQueue
+	sharedLocalQueueOnce sync.Once
+)
+
+func getJobQueue(ctx context.Context, env *envConfig, aws functionInvoker) (jobQueue, error) {
+	mode := env.QueueMode
+	if mode == "" {
+		// Running on AWS Lambda if the function name is set
+		mode = localQueueMode
+		if env.FunctionName != "" {
+			mode = lambdaQueueMode
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("queue mode: %+v\n", mode)
+
+	switch mode {
+	case lambdaQueueMode:
+		if env.FunctionName == "" {
+			return nil, errors.New("The function name is required for the lambda queue")
+		}
+		return &lambdaQueue{aws: aws, functionName: env.FunctionName}, nil
+	case localQueueMode:
+		sharedLocalQueueOnce.Do(func() {
+			sharedLocalQueue = newLocalQueue(4, func(job *commandJob) {
+				runJob(context.Background(), job)
+			})
+		})
+		return sharedLocalQueue, nil
+	case inlineQueueMode:
+		return &inlineQueue{}, nil
+	default:
+		return nil, errors.New("Unknown queue mode: " + mode)
+	}
+}
+
+func runJob(ctx context.Context, job *commandJob) error {
+	ctx = logging.NewContext(ctx, logging.Fields{"event_id": job.EventID})
+
+	// Load information from environment variables and make it available on a global basis
+	env, err := backends.env()
+	if err != nil {
+		return err
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("job: %+v\n", job)
+
+	// Initialize the clients
+	sc := backends.slack(env)
+	aws, err := backends.aws(env)
+	if err != nil {
+		return err
+	}
+
+	// The request has already been verified when it was accepted
+	var cmd *commandParameter
+	switch job.Kind {
+	case slashCommandJob:
+		s, result, err := sc.parseSlashCommand(ctx, job.Body)
+		if err != nil || result != "" {
+			logger(ctx).Println("[ERROR] The job could not be parsed: ", job.Kind, err)
+			return err
+		}
+		cmd = parseSlashCommandParameter(ctx, s, env.SlashResponseType)
+	case interactiveJob:
+		callback, err := parseInteraction(ctx, job.Body)
+		if err != nil {
+			logger(ctx).Println("[ERROR] The job could not be parsed: ", job.Kind, err)
+			return err
+		}
+		cmd = parseInteractionParameter(ctx, callback, env.SlashResponseType)
+	default:
+		se, result, err := sc.parseEvent(ctx, job.Body)
+		if err != nil || result != "" {
+			logger(ctx).Println("[ERROR] The job could not be parsed: ", job.EventID, err)
+			aws.completeMutexItem(ctx, env.MutexTableName, job.EventID, storage.MutexFailed, result)
+			return err
+		}
+		cmd = parseCommand(ctx, se)
+	}
+
+	// Actually execute the command
+	ctx = logging.NewContext(ctx, cmd.logFields())
+	err = cmd.runCommand(ctx, sc, aws)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Processing failed: ", err)
+	}
+
+	// Record the outcome so that a retried event can be answered with it
+	if job.EventID != "" {
+		status := storage.MutexSucceeded
+		result := `{"result": "ok"}`
+		if err != nil {
+			b, _ := json.Marshal(map[string]string{"result": "failed", "message": err.Error()})
+			status, result = storage.MutexFailed, string(b)
+		}
+		aws.completeMutexItem(ctx, env.MutexTableName, job.EventID, status, result)
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestLocalQueue(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	q := newLocalQueue(2, func(job *commandJob) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, job.EventID)
+	})
+
+	for _, id := range []string{"Ev0001", "Ev0002", "Ev0003"} {
+		if err := q.enqueue(context.Background(), &commandJob{Kind: eventJob, EventID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The jobs are finished before close returns
+	q.close()
+	if len(ran) != 3 {
+		t.Errorf("ran %v", ran)
+	}
+}
+
+func TestLocalQueueFull(t *testing.T) {
+	// Without workers, the jobs stay in the queue
+	q := newLocalQueue(0, func(job *commandJob) {})
+	defer q.close()
+
+	for i := 0; i < localQueueSize; i++ {
+		if err := q.enqueue(context.Background(), &commandJob{}); err != nil {
+			t.Fatalf("job %d: %v", i+1, err)
+		}
+	}
+	if err := q.enqueue(context.Background(), &commandJob{}); err != errQueueFull {
+		t.Errorf("enqueue() error = %v, want %v", err, errQueueFull)
+	}
+}
+
+func TestLocalQueueCanceled(t *testing.T) {
+	q := newLocalQueue(0, func(job *commandJob) {})
+	defer q.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.enqueue(ctx, &commandJob{}); err != context.Canceled {
+		t.Errorf("enqueue() error = %v, want %v", err, context.Canceled)
+	}
+	if len(q.jobs) != 0 {
+		t.Errorf("%d jobs are queued", len(q.jobs))
+	}
+}
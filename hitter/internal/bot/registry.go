@@ -0,0 +1,336 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Each command declares its name, arguments, options and handler here,
+// and parsing, validation and help are all driven from that declaration.
+// To add a command, create a new file and call registerCommand in init().
+
+type valueKind int
+
+const (
+	textValue valueKind = iota
+	intValue
+	urlValue
+	userValue
+	boolValue
+	usergroupValue
+	channelValue
+)
+
+type argumentSpec struct {
+	name        string
+	description string
+	kind        valueKind
+	required    bool
+	// Take the rest of the input text as is, the kind is not checked
+	rest         bool
+	defaultValue string
+	// Only used for intValue, zero means no limit
+	min int
+	max int
+}
+
+type optionSpec struct {
+	// Including the leading "--"
+	name         string
+	description  string
+	kind         valueKind
+	repeatable   bool
+	defaultValue string
+	// Only used for intValue, zero means no limit
+	min int
+	max int
+}
+
+type permission int
+
+const (
+	// Only users listed in ADMIN_USER_IDS can run the command
+	adminPermission permission = iota + 1
+)
+
+type commandHandler func(c *commandParameter, ctx context.Context, sc *slackClient, aws awsServices) error
+
+type commandSpec struct {
+	name        string
+	aliases     []string
+	description string
+	// Additional lines for the description in the help
+	notes []string
+	// Input after the bot mention, such as "hit 2"
+	examples    []string
+	arguments   []*argumentSpec
+	options     []*optionSpec
+	permissions []permission
+	// The command takes the files attached to the message, which hitterctl reads from the local paths
+	attachments bool
+	handler     commandHandler
+}
+
+// Options accepted by all commands
+var globalOptions = []*optionSpec{
+	{name: "--private", kind: boolValue, description: "Only show the result to you"},
+}
+
+type commandRegistry struct {
+	commands map[string]*commandSpec
+	aliases  map[string]string
+}
+
+var registry = newCommandRegistry()
+
+func newCommandRegistry() *commandRegistry {
+	r := &commandRegistry{}
+	r.commands = make(map[string]*commandSpec)
+	r.aliases = make(map[string]string)
+
+	return r
+}
+
+func registerCommand(spec *commandSpec) {
+	registry.register(spec)
+}
+
+func (r *commandRegistry) register(spec *commandSpec) {
+	// Registration happens in init(), so a conflict is a programming error
+	for _, name := range append([]string{spec.name}, spec.aliases...) {
+		if _, ok := r.commands[name]; ok {
+			panic("command already registered: " + name)
+		}
+		if _, ok := r.aliases[name]; ok {
+			panic("command alias already registered: " + name)
+		}
+	}
+
+	r.commands[spec.name] = spec
+	for _, alias := range spec.aliases {
+		r.aliases[alias] = spec.name
+	}
+}
+
+func (r *commandRegistry) lookup(name string) (*commandSpec, bool) {
+	if n, ok := r.aliases[name]; ok {
+		name = n
+	}
+	spec, ok := r.commands[name]
+
+	return spec, ok
+}
+
+func (r *commandRegistry) list() []*commandSpec {
+	var specs []*commandSpec
+	for _, spec := range r.commands {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].name < specs[j].name
+	})
+
+	return specs
+}
+
+func (s *commandSpec) lookupOption(name string) (*optionSpec, bool) {
+	for _, o := range append(s.options, globalOptions...) {
+		if o.name == name {
+			return o, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *commandSpec) bind(ctx context.Context, c *commandParameter) error {
+	c.arguments = make(map[string]string)
+	c.options = make(map[string][]string)
+	c.defaulted = make(map[string]bool)
+
+	z := &tokenizer{text: c.body}
+	var positional []string
+	optionsEnded := false
+	for {
+		// An argument that takes the rest of the text as is, including new lines and unbalanced quotes
+		if len(positional) < len(s.arguments) && s.arguments[len(positional)].rest {
+			a := s.arguments[len(positional)]
+			start := z.skipSpaces()
+			if start < len(c.body) && (optionsEnded || !strings.HasPrefix(c.body[start:], "--")) {
+				c.arguments[a.name] = unescapeSlackText(strings.TrimSpace(c.body[start:]))
+				positional = append(positional, c.arguments[a.name])
+				break
+			}
+		}
+
+		t, err := z.next()
+		if err != nil {
+			return c.withCommandName(err)
+		}
+		if t == nil {
+			break
+		}
+		// Output debug log
+		logger(ctx).Debugf("token: %+v\n", t)
+
+		// "--" alone means the end of the options
+		if !t.quoted && !optionsEnded && t.value == "--" {
+			optionsEnded = true
+			continue
+		}
+
+		// Options are strings that begin with "--"
+		if !t.quoted && !optionsEnded && strings.HasPrefix(t.value, "--") {
+			name := t.value
+			val := ""
+			hasValue := false
+			// --opt=value
+			if j := strings.Index(name, "="); j >= 0 {
+				name, val, hasValue = name[:j], name[j+1:], true
+			}
+
+			o, ok := s.lookupOption(name)
+			if !ok {
+				return c.withCommandName(newTokenError(t, c.body, "Unknown option for "+s.name))
+			}
+			if _, ok := c.options[o.name]; ok && !o.repeatable {
+				return c.withCommandName(newTokenError(t, c.body, "The option can only be specified once"))
+			}
+
+			// Boolean flags do not take the following string as a value
+			if o.kind == boolValue && !hasValue {
+				val, hasValue = "true", true
+			}
+
+			// The value of the option is the string that follows it
+			vt := t
+			if !hasValue {
+				vt, err = z.next()
+				if err != nil {
+					return c.withCommandName(err)
+				}
+				if vt == nil {
+					return c.withCommandName(newTokenError(t, c.body, "The option requires a value"))
+				}
+				logger(ctx).Debugf("token: %+v\n", vt)
+				val = vt.value
+			}
+
+			v, err := convertValue(o.kind, vt.typed(val), o.min, o.max)
+			if err != nil {
+				return c.withCommandName(newTokenError(vt, c.body, "Invalid value for "+o.name+", "+err.Error()))
+			}
+			c.options[o.name] = append(c.options[o.name], v)
+			continue
+		}
+
+		if len(positional) >= len(s.arguments) {
+			return c.withCommandName(newTokenError(t, c.body, "Too many arguments for "+s.name))
+		}
+		a := s.arguments[len(positional)]
+
+		v, err := convertValue(a.kind, t, a.min, a.max)
+		if err != nil {
+			return c.withCommandName(newTokenError(t, c.body, "Invalid value for <"+a.name+">, "+err.Error()))
+		}
+		c.arguments[a.name] = v
+		positional = append(positional, v)
+	}
+
+	// Check for required arguments and apply default values
+	for _, a := range s.arguments {
+		if _, ok := c.arguments[a.name]; ok {
+			continue
+		}
+		if a.required {
+			return fmt.Errorf("The argument is required: <%s>", a.name)
+		}
+		if a.defaultValue != "" {
+			c.arguments[a.name] = a.defaultValue
+			c.defaulted[a.name] = true
+		}
+	}
+	for _, o := range s.options {
+		if _, ok := c.options[o.name]; !ok && o.defaultValue != "" {
+			c.options[o.name] = []string{o.defaultValue}
+			c.defaulted[o.name] = true
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("arguments: %+v\n", c.arguments)
+	logger(ctx).Debugf("options: %+v\n", c.options)
+
+	return nil
+}
+
+func (s *commandSpec) checkPermissions(ctx context.Context, c *commandParameter) error {
+	for _, p := range s.permissions {
+		switch p {
+		case adminPermission:
+			if !containsString(envconf.AdminUserIDs, c.from) {
+				logger(ctx).Println("[REJECTED] The user is not allowed to run the command: ", c.from, s.name)
+				return fmt.Errorf("You are not allowed to run the %s command", s.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func convertValue(kind valueKind, t *token, min int, max int) (string, error) {
+	str := strings.TrimSpace(t.value)
+
+	switch kind {
+	case intValue:
+		num, err := strconv.Atoi(str)
+		if err != nil {
+			return "", errors.New("not a number")
+		}
+		if min != 0 && num < min {
+			return "", fmt.Errorf("must be at least %d", min)
+		}
+		if max != 0 && num > max {
+			return "", fmt.Errorf("must be at most %d", max)
+		}
+	case urlValue:
+		// URLs are enclosed in "<>" like this: "<https://docs.aws.amazon.com/cdk/api/latest/>"
+		if !strings.HasPrefix(str, "http://") && !strings.HasPrefix(str, "https://") {
+			return "", errors.New("not a URL")
+		}
+	case boolValue:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return "", errors.New("not a boolean")
+		}
+		str = strconv.FormatBool(b)
+	case userValue:
+		if t.kind != userToken {
+			return "", errors.New("not a mention")
+		}
+	case usergroupValue:
+		if t.kind != usergroupToken {
+			return "", errors.New("not a user group")
+		}
+	case channelValue:
+		if t.kind != channelToken {
+			return "", errors.New("not a channel")
+		}
+	}
+
+	return str, nil
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
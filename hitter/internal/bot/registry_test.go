@@ -0,0 +1,284 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+var bindTestSpec = &commandSpec{
+	name: "test",
+	arguments: []*argumentSpec{
+		{name: "number", kind: intValue, defaultValue: "1", min: 1, max: 10},
+		{name: "name", kind: textValue},
+		{name: "text", kind: textValue, rest: true},
+	},
+	options: []*optionSpec{
+		{name: "--ex", kind: userValue, repeatable: true},
+		{name: "--fair", kind: boolValue},
+		{name: "--window", kind: intValue, defaultValue: "10", min: 1, max: 100},
+		{name: "--items", kind: textValue},
+		{name: "--url", kind: urlValue},
+	},
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantArguments map[string]string
+		wantOptions   map[string][]string
+	}{
+		{
+			name:          "defaults",
+			body:          "",
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "repeated options",
+			body:          " 2 --ex <@U0001> --ex=<@W017HPXHDF0>",
+			wantArguments: map[string]string{"number": "2"},
+			wantOptions:   map[string][]string{"--ex": {"U0001", "W017HPXHDF0"}, "--window": {"10"}},
+		},
+		{
+			name:          "boolean flag does not take the next value",
+			body:          " --fair 3",
+			wantArguments: map[string]string{"number": "3"},
+			wantOptions:   map[string][]string{"--fair": {"true"}, "--window": {"10"}},
+		},
+		{
+			name:          "boolean flag with a value",
+			body:          " --fair=false",
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--fair": {"false"}, "--window": {"10"}},
+		},
+		{
+			name:          "option values",
+			body:          ` --window=5 --items "code review" --url <https://example.com>`,
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--window": {"5"}, "--items": {"code review"}, "--url": {"https://example.com"}},
+		},
+		{
+			name:          "option value that looks like an option",
+			body:          " --items --fair",
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--items": {"--fair"}, "--window": {"10"}},
+		},
+		{
+			name:          "global option",
+			body:          " 2 --private",
+			wantArguments: map[string]string{"number": "2"},
+			wantOptions:   map[string][]string{"--private": {"true"}, "--window": {"10"}},
+		},
+		{
+			name:          "end of options",
+			body:          " 2 -- --fair",
+			wantArguments: map[string]string{"number": "2", "name": "--fair"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "quoted option",
+			body:          ` 2 "--fair"`,
+			wantArguments: map[string]string{"number": "2", "name": "--fair"},
+			wantOptions:   map[string][]string{"--window": {"10"}},
+		},
+		{
+			name:          "rest of the text",
+			body:          " 2 --fair abc  some \"long\" text\nand &lt;more&gt; ",
+			wantArguments: map[string]string{"number": "2", "name": "abc", "text": "some \"long\" text\nand <more>"},
+			wantOptions:   map[string][]string{"--fair": {"true"}, "--window": {"10"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &commandParameter{command: "test", body: tt.body}
+			if err := bindTestSpec.bind(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.arguments, tt.wantArguments) {
+				t.Errorf("arguments = %v, want %v", c.arguments, tt.wantArguments)
+			}
+			if !reflect.DeepEqual(c.options, tt.wantOptions) {
+				t.Errorf("options = %v, want %v", c.options, tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantError   string
+		wantPointer string
+	}{
+		{
+			name:        "unknown option",
+			body:        " 2 --unknown",
+			wantError:   "Unknown option for test: --unknown (column 8)",
+			wantPointer: "test 2 --unknown\n       ^^^^^^^^^",
+		},
+		{
+			name:        "unknown option with a value",
+			body:        " --ex=<@U0001> --fiar=true",
+			wantError:   "Unknown option for test: --fiar=true (column 20)",
+			wantPointer: "test --ex=<@U0001> --fiar=true\n                   ^^^^^^^^^^^",
+		},
+		{
+			name:        "option specified twice",
+			body:        " --fair --fair",
+			wantError:   "The option can only be specified once: --fair (column 13)",
+			wantPointer: "test --fair --fair\n            ^^^^^^",
+		},
+		{
+			name:        "option without a value",
+			body:        " 2 --window",
+			wantError:   "The option requires a value: --window (column 8)",
+			wantPointer: "test 2 --window\n       ^^^^^^^^",
+		},
+		{
+			name:        "invalid option value",
+			body:        " --window 0",
+			wantError:   "Invalid value for --window, must be at least 1: 0 (column 15)",
+			wantPointer: "test --window 0\n              ^",
+		},
+		{
+			name:        "invalid option value after equals",
+			body:        " --window=abc",
+			wantError:   "Invalid value for --window, not a number: --window=abc (column 6)",
+			wantPointer: "test --window=abc\n     ^^^^^^^^^^^^",
+		},
+		{
+			name:        "invalid boolean",
+			body:        " --fair=maybe",
+			wantError:   "Invalid value for --fair, not a boolean: --fair=maybe (column 6)",
+			wantPointer: "test --fair=maybe\n     ^^^^^^^^^^^^",
+		},
+		{
+			name:        "not a mention",
+			body:        " --ex U0001",
+			wantError:   "Invalid value for --ex, not a mention: U0001 (column 11)",
+			wantPointer: "test --ex U0001\n          ^^^^^",
+		},
+		{
+			name:        "invalid argument",
+			body:        " 11",
+			wantError:   "Invalid value for <number>, must be at most 10: 11 (column 6)",
+			wantPointer: "test 11\n     ^^",
+		},
+		{
+			name:        "unterminated quote",
+			body:        ` 2 --items "code review`,
+			wantError:   `The quote is not closed: "code review (column 16)`,
+			wantPointer: "test 2 --items \"code review\n               ^^^^^^^^^^^^",
+		},
+		{
+			name:        "multibyte input before the error",
+			body:        " 2 名前 --unknown",
+			wantError:   "Unknown option for test: --unknown (column 11)",
+			wantPointer: "test 2 名前 --unknown\n          ^^^^^^^^^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &commandParameter{command: "test", body: tt.body}
+			err := bindTestSpec.bind(context.Background(), c)
+			te, ok := err.(*tokenError)
+			if !ok {
+				t.Fatalf("bind(%q) error = %v, want a tokenError", tt.body, err)
+			}
+			if te.Error() != tt.wantError {
+				t.Errorf("Error() = %q, want %q", te.Error(), tt.wantError)
+			}
+			if te.pointer() != tt.wantPointer {
+				t.Errorf("pointer() =\n%s\nwant\n%s", te.pointer(), tt.wantPointer)
+			}
+		})
+	}
+}
+
+func TestBindTooManyArguments(t *testing.T) {
+	spec := &commandSpec{
+		name:      "test",
+		arguments: []*argumentSpec{{name: "number", kind: intValue}},
+	}
+
+	c := &commandParameter{command: "test", body: " 1 2"}
+	err := spec.bind(context.Background(), c)
+	if err == nil || err.Error() != "Too many arguments for test: 2 (column 8)" {
+		t.Errorf("bind() error = %v", err)
+	}
+}
+
+func TestBindRequiredArgument(t *testing.T) {
+	spec := &commandSpec{
+		name:      "test",
+		arguments: []*argumentSpec{{name: "url", kind: urlValue, required: true}},
+	}
+
+	c := &commandParameter{command: "test", body: " --private"}
+	err := spec.bind(context.Background(), c)
+	if err == nil || err.Error() != "The argument is required: <url>" {
+		t.Errorf("bind() error = %v", err)
+	}
+}
+
+func TestBindDefaulted(t *testing.T) {
+	c := &commandParameter{command: "test", body: " 1 --window 10"}
+	if err := bindTestSpec.bind(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+
+	// The values given explicitly are not taken as the defaults, even if they are the same
+	if c.defaulted["number"] || c.defaulted["--window"] {
+		t.Errorf("defaulted = %v", c.defaulted)
+	}
+
+	c = &commandParameter{command: "test", body: ""}
+	if err := bindTestSpec.bind(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"number": true, "--window": true}; !reflect.DeepEqual(c.defaulted, want) {
+		t.Errorf("defaulted = %v, want %v", c.defaulted, want)
+	}
+}
+
+func TestBindRestText(t *testing.T) {
+	translate, _ := registry.lookup("translate")
+
+	// The free text is not tokenized, so quotes and backslashes are taken as they are
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: ` I'm "happy`, want: `I'm "happy`},
+		{body: ` He said “hi`, want: `He said “hi`},
+		{body: ` path C:\`, want: `path C:\`},
+		{body: ` 'quoted`, want: `'quoted`},
+		{body: " -- " + `"--private`, want: `"--private`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			c := &commandParameter{command: "translate", body: tt.body}
+			if err := translate.bind(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.arguments["text"]; got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	c := &commandParameter{command: "test", body: ` 2 abc --fair "unbalanced`}
+	if err := bindTestSpec.bind(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	// The options before the rest of the text are still parsed
+	if got, want := c.arguments["text"], `"unbalanced`; got != want || c.options["--fair"] == nil {
+		t.Errorf("text = %q, want %q, options = %v", got, want, c.options)
+	}
+}
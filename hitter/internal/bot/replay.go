@@ -0,0 +1,289 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/config"
+	"github.com/uchimanajet7/hitter/hitter/internal/slacktest"
+)
+
+// ReplayOptions configures the backends used by Replay.
+type ReplayOptions struct {
+	// Use the environment variables, slack and AWS instead of the fake backends
+	Real bool
+	// Members of each channel on the fake slack server, by channel ID
+	Members map[string][]string
+	// Write each result as a JSON line instead of text
+	JSON bool
+}
+
+// ReplayResult is the outcome of one replayed event.
+type ReplayResult struct {
+	Line       int             `json:"line"`
+	EventID    string          `json:"event_id,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Body       string          `json:"body"`
+	Calls      []*ReplayedCall `json:"calls"`
+}
+
+// ReplayedCall is an HTTP request sent to slack while handling an event.
+type ReplayedCall struct {
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	Form       url.Values      `json:"form,omitempty"`
+	JSON       json.RawMessage `json:"json,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Response   string          `json:"response"`
+}
+
+// The prefix of the debug log written by parseEvent, so the log can be replayed as it is
+const replayLogPrefix = "eventJSON: "
+
+// Replay feeds the slack events read from r, one JSON per line, through the event handler
+// and writes the slack API calls made for each event to w.
+// The commands are executed before the next event is read.
+func Replay(r io.Reader, w io.Writer, opts ReplayOptions) error {
+	env, aws, recorder, closeFn, err := newReplayBackends(opts)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	saved := *backends
+	defer func() { *backends = saved }()
+	backends.env = func() (*envConfig, error) {
+		envconf = env
+		return env, nil
+	}
+	backends.slack = func(env *envConfig) *slackClient {
+		client := &http.Client{Transport: recorder}
+		return newSlackClient(env.SlackOAuthAccessToken, env.SlackAPIURL, slack.OptionHTTPClient(client))
+	}
+	backends.aws = aws
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		eventJSON := strings.TrimSpace(scanner.Text())
+		// The debug log is written as a JSON line with the message in "msg"
+		entry := struct {
+			Msg string `json:"msg"`
+		}{}
+		if json.Unmarshal([]byte(eventJSON), &entry) == nil && strings.HasPrefix(entry.Msg, replayLogPrefix) {
+			eventJSON = entry.Msg
+		}
+		if i := strings.Index(eventJSON, replayLogPrefix); i >= 0 {
+			eventJSON = strings.TrimSpace(eventJSON[i+len(replayLogPrefix):])
+		}
+		if eventJSON == "" || strings.HasPrefix(eventJSON, "#") {
+			continue
+		}
+
+		recorder.reset()
+		envconf = env
+		res, err := handleEvent(context.Background(), env, eventJSON, nil)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+
+		result := &ReplayResult{Line: line, StatusCode: res.StatusCode, Body: res.Body, Calls: recorder.calls()}
+		se := &slackEvent{}
+		if err := json.Unmarshal([]byte(eventJSON), se); err == nil {
+			result.EventID = se.EventID
+		}
+
+		if err := writeReplayResult(w, result, opts.JSON); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func newReplayBackends(opts ReplayOptions) (*envConfig, func(*envConfig) (awsServices, error), *replayRecorder, func(), error) {
+	if opts.Real {
+		env, err := loadEnvConfig()
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		env.QueueMode = inlineQueueMode
+
+		return env, backends.aws, &replayRecorder{base: http.DefaultTransport}, func() {}, nil
+	}
+
+	server := slacktest.NewServer()
+	for channel, users := range opts.Members {
+		for _, id := range users {
+			server.AddUser(channel, slack.User{ID: id, IsBot: strings.HasPrefix(id, "B")})
+		}
+	}
+
+	env := &envConfig{
+		Common:                config.Common{URLTableName: "HitterURLTable"},
+		SlackOAuthAccessToken: "xoxb-replay",
+		MutexTableName:        "HitterMutexTable",
+		StateTableName:        "HitterStateTable",
+		S3BucketName:          "hitter-replay",
+		APIBaseURL:            "https://short.example.com/v1/",
+		SlackAPIURL:           server.APIURL(),
+		SlashResponseType:     "in_channel",
+		SlackSignatureMaxAge:  300,
+		QueueMode:             inlineQueueMode,
+	}
+
+	// The same services are kept between the events, so a repeated event ID is rejected
+	aws := newMemoryAWS()
+	newAws := func(*envConfig) (awsServices, error) {
+		return aws, nil
+	}
+
+	// Files attached to the events are not downloaded from slack
+	apiURL, _ := url.Parse(server.APIURL())
+	recorder := &replayRecorder{base: http.DefaultTransport, offline: apiURL.Host}
+
+	return env, newAws, recorder, server.Close, nil
+}
+
+// Records the requests sent by the slack client
+type replayRecorder struct {
+	base http.RoundTripper
+	// Requests to other hosts are answered without the network when set
+	offline string
+
+	mu      sync.Mutex
+	history []*ReplayedCall
+}
+
+func (r *replayRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The query is shown in the form instead
+	u := *req.URL
+	u.RawQuery = ""
+	call := &ReplayedCall{Method: req.Method, URL: u.String()}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		call.Form, call.JSON = decodeReplayBody(req, body)
+	}
+	if call.Form == nil && len(req.URL.Query()) > 0 {
+		call.Form = req.URL.Query()
+	}
+	// Do not print the token of the real workspace
+	if call.Form.Get("token") != "" {
+		call.Form.Set("token", "[REDACTED]")
+	}
+
+	var res *http.Response
+	if r.offline != "" && req.URL.Host != r.offline {
+		res = &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader("replayed content of " + req.URL.String())),
+			Request:    req,
+		}
+	} else {
+		var err error
+		res, err = r.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	call.StatusCode = res.StatusCode
+	call.Response = string(body)
+
+	r.mu.Lock()
+	r.history = append(r.history, call)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+func (r *replayRecorder) reset() {
+	r.mu.Lock()
+	r.history = nil
+	r.mu.Unlock()
+}
+
+func (r *replayRecorder) calls() []*ReplayedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*ReplayedCall{}, r.history...)
+}
+
+func decodeReplayBody(req *http.Request, body []byte) (url.Values, json.RawMessage) {
+	contentType := req.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return values, nil
+		}
+	case strings.HasPrefix(contentType, "application/json"):
+		if json.Valid(body) {
+			return nil, json.RawMessage(body)
+		}
+	}
+
+	// Multipart uploads carry their parameters in the query
+	return nil, nil
+}
+
+func writeReplayResult(w io.Writer, result *ReplayResult, asJSON bool) error {
+	if asJSON {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "=== line %d event %s\n", result.Line, result.EventID)
+	fmt.Fprintf(buf, "response: %d %s\n", result.StatusCode, result.Body)
+	for _, c := range result.Calls {
+		fmt.Fprintf(buf, "--> %s %s\n", c.Method, c.URL)
+		keys := make([]string, 0, len(c.Form))
+		for k := range c.Form {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(buf, "    %s: %s\n", k, strings.Join(c.Form[k], ", "))
+		}
+		if len(c.JSON) > 0 {
+			fmt.Fprintf(buf, "    %s\n", c.JSON)
+		}
+		fmt.Fprintf(buf, "<-- %d %s\n", c.StatusCode, strings.TrimSpace(c.Response))
+	}
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
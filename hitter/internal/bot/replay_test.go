@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	event := `{"type":"event_callback","event_id":"Ev0001","event":{"type":"app_mention","text":"<@UHITTERBOT> hit","user":"U0001","channel":"C0123","ts":"1595673533.002100","event_ts":"1595673533.002100"}}`
+	logLine, _ := json.Marshal(map[string]string{"level": "debug", "msg": replayLogPrefix + event})
+	input := strings.Join([]string{
+		event,
+		"",
+		"2020/07/25 10:00:00 [DEBUG] " + replayLogPrefix + event,
+		string(logLine),
+	}, "\n")
+
+	out := &bytes.Buffer{}
+	opts := ReplayOptions{Members: map[string][]string{"C0123": {"U0001", "U0002", "B0001"}}, JSON: true}
+	if err := Replay(strings.NewReader(input), out, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d results, want 3:\n%s", len(lines), out.String())
+	}
+
+	first := &ReplayResult{}
+	if err := json.Unmarshal([]byte(lines[0]), first); err != nil {
+		t.Fatal(err)
+	}
+	if first.EventID != "Ev0001" || first.StatusCode != 200 || !strings.Contains(first.Body, "accepted") {
+		t.Errorf("first result = %+v", first)
+	}
+	posted := false
+	for _, c := range first.Calls {
+		if strings.HasSuffix(c.URL, "/chat.postMessage") && c.Form.Get("channel") == "C0123" {
+			posted = true
+		}
+	}
+	if !posted {
+		t.Errorf("chat.postMessage was not called: %+v", first.Calls)
+	}
+
+	// The same event ID read from the debug log is answered with the stored result
+	for i, want := range []int{3, 4} {
+		res := &ReplayResult{}
+		if err := json.Unmarshal([]byte(lines[i+1]), res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Line != want || res.EventID != "Ev0001" || len(res.Calls) != 0 {
+			t.Errorf("result %d = %+v", i+1, res)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"path"
+	"strings"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// CommandResult is the outcome of a command, kept for the callers other than slack such as hitterctl.
+type CommandResult struct {
+	Command string      `json:"command"`
+	Error   string      `json:"error,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+type helpResult struct {
+	Text string `json:"text"`
+}
+
+type hitResult struct {
+	Users []string `json:"users"`
+}
+
+type orderResult struct {
+	Users  []string `json:"users"`
+	Starts []string `json:"starts,omitempty"`
+	Ends   string   `json:"ends,omitempty"`
+}
+
+type pairResult struct {
+	Groups [][]string `json:"groups"`
+	// Number of pairs that met in the recent rounds
+	Repeated int `json:"repeated"`
+	// Groups whose introduction could not be posted with --dm
+	Failed [][]string `json:"failed,omitempty"`
+}
+
+type teamsResult struct {
+	Teams [][]string `json:"teams"`
+}
+
+type rotationResult struct {
+	Name  string   `json:"name"`
+	Order []string `json:"order"`
+	Next  string   `json:"next,omitempty"`
+}
+
+type translateResult struct {
+	Source         string `json:"source"`
+	SourceLanguage string `json:"source_language"`
+	Text           string `json:"text"`
+	Language       string `json:"language"`
+}
+
+type linkResult struct {
+	File    string `json:"file"`
+	URL     string `json:"url"`
+	Expires string `json:"expires"`
+}
+
+type shortResult struct {
+	URL     string `json:"url"`
+	Expires string `json:"expires"`
+}
+
+func newLinkResults(items []*storage.S3Item) []*linkResult {
+	results := []*linkResult{}
+	for _, v := range items {
+		results = append(results, &linkResult{File: path.Base(v.Key), URL: v.PreSignedURL, Expires: v.URLExpiry})
+	}
+
+	return results
+}
+
+func newOrderResult(users []string, starts []string) *orderResult {
+	result := &orderResult{Users: users}
+	if len(starts) > len(users) {
+		result.Starts = starts[:len(users)]
+		result.Ends = starts[len(users)]
+	}
+
+	return result
+}
+
+func newRotationResult(name string, state *rotationState) *rotationResult {
+	result := &rotationResult{Name: name, Order: append([]string{}, state.Order...)}
+	if state.Next < len(state.Order) {
+		result.Next = state.Order[state.Next]
+	}
+
+	return result
+}
+
+func (c *slackClient) record(cp *commandParameter, result interface{}) {
+	c.result = &CommandResult{Command: cp.command, Result: result}
+}
+
+// Text returns the result as plain text, one value per line with tab separated fields.
+func (r *CommandResult) Text() string {
+	if r.Error != "" {
+		return r.Error
+	}
+
+	var lines []string
+	switch v := r.Result.(type) {
+	case *helpResult:
+		lines = append(lines, v.Text)
+	case *hitResult:
+		lines = append(lines, v.Users...)
+	case *orderResult:
+		for i, u := range v.Users {
+			if len(v.Starts) > i {
+				u += "\t" + v.Starts[i]
+			}
+			lines = append(lines, u)
+		}
+	case *pairResult:
+		// One group per line
+		for _, g := range v.Groups {
+			lines = append(lines, strings.Join(g, "\t"))
+		}
+	case *teamsResult:
+		// One team per line
+		for _, t := range v.Teams {
+			lines = append(lines, strings.Join(t, "\t"))
+		}
+	case *rotationResult:
+		// The next member is marked with "*"
+		for _, u := range v.Order {
+			if u == v.Next {
+				u += "\t*"
+			}
+			lines = append(lines, u)
+		}
+	case *translateResult:
+		lines = append(lines, v.Text)
+	case []*linkResult:
+		for _, l := range v {
+			lines = append(lines, l.File+"\t"+l.URL+"\t"+l.Expires)
+		}
+	case *shortResult:
+		lines = append(lines, v.URL+"\t"+v.Expires)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+)
+
+// Slack retries the event when the response is not returned in time or is not successful.
+// https://api.slack.com/apis/connections/events-api#the-events-api__field-guide__error-handling__graceful-retries
+const (
+	slackRetryNumHeader    = "X-Slack-Retry-Num"
+	slackRetryReasonHeader = "X-Slack-Retry-Reason"
+	slackNoRetryHeader     = "X-Slack-No-Retry"
+)
+
+type retryAction int
+
+const (
+	// Process the event as usual, answering with the stored result if it has already been processed
+	replayRetry retryAction = iota
+	// Process the event again if the previous attempt failed or did not reach the bot
+	rerunRetry
+)
+
+func (a retryAction) String() string {
+	switch a {
+	case rerunRetry:
+		return "rerun"
+	default:
+		return "replay"
+	}
+}
+
+// The action to take for each retry reason
+var retryActions = map[string]retryAction{
+	// The first attempt was slow to respond, it may still be running or may have failed to be accepted
+	"http_timeout": rerunRetry,
+	// The first attempt failed to be accepted, such as failing to enqueue the command
+	"http_error": rerunRetry,
+	// The first attempt may not have reached the bot
+	"connection_failed":  replayRetry,
+	"ssl_error":          replayRetry,
+	"too_many_redirects": replayRetry,
+	"unknown_error":      replayRetry,
+}
+
+type retryInfo struct {
+	num    int
+	reason string
+	action retryAction
+}
+
+// Read the retry from the headers of the Events API request
+func parseRetry(ctx context.Context, headers map[string]string) *retryInfo {
+	num, _ := strconv.Atoi(getHeader(headers, slackRetryNumHeader))
+
+	return newRetryInfo(ctx, num, getHeader(headers, slackRetryReasonHeader))
+}
+
+// The retry of the event and the action for the reason, or nil if it is the first attempt.
+// Socket Mode passes the retry in the envelope instead of the headers.
+func newRetryInfo(ctx context.Context, num int, reason string) *retryInfo {
+	if num <= 0 {
+		// Not a retry
+		return nil
+	}
+
+	ri := &retryInfo{num: num, reason: reason, action: replayRetry}
+	if a, ok := retryActions[reason]; ok {
+		ri.action = a
+	}
+
+	logger(ctx).Printf("[RETRY] Slack retried the event: num=%d reason=%s action=%s\n", ri.num, ri.reason, ri.action)
+
+	return ri
+}
+
+// Tell slack not to retry the event any more
+func noRetryHeaders() map[string]string {
+	return map[string]string{slackNoRetryHeader: "1"}
+}
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/config"
+	"github.com/uchimanajet7/hitter/hitter/internal/slacktest"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+func TestParseRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantNil    bool
+		wantNum    int
+		wantAction retryAction
+	}{
+		{name: "first attempt", headers: map[string]string{}, wantNil: true},
+		{name: "invalid number", headers: map[string]string{"X-Slack-Retry-Num": "first"}, wantNil: true},
+		{name: "http_timeout", headers: map[string]string{"X-Slack-Retry-Num": "1", "X-Slack-Retry-Reason": "http_timeout"}, wantNum: 1, wantAction: rerunRetry},
+		{name: "http_error", headers: map[string]string{"x-slack-retry-num": "2", "x-slack-retry-reason": "http_error"}, wantNum: 2, wantAction: rerunRetry},
+		{name: "connection_failed", headers: map[string]string{"X-Slack-Retry-Num": "3", "X-Slack-Retry-Reason": "connection_failed"}, wantNum: 3, wantAction: replayRetry},
+		{name: "unknown reason", headers: map[string]string{"X-Slack-Retry-Num": "1", "X-Slack-Retry-Reason": "new_reason"}, wantNum: 1, wantAction: replayRetry},
+		{name: "no reason", headers: map[string]string{"X-Slack-Retry-Num": "1"}, wantNum: 1, wantAction: replayRetry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := parseRetry(context.Background(), tt.headers)
+			if tt.wantNil {
+				if ri != nil {
+					t.Errorf("got %+v, want nil", ri)
+				}
+				return
+			}
+			if ri == nil || ri.num != tt.wantNum || ri.action != tt.wantAction {
+				t.Errorf("got %+v, want num=%d action=%s", ri, tt.wantNum, tt.wantAction)
+			}
+		})
+	}
+}
+
+// Runs handleEvent with the events enqueued to fakeInvoker
+func setupRetryTest(t *testing.T) (*envConfig, *memoryAWS) {
+	t.Helper()
+
+	server := slacktest.NewServer()
+	t.Cleanup(server.Close)
+
+	env := &envConfig{
+		Common:                config.Common{URLTableName: "HitterURLTable"},
+		SlackOAuthAccessToken: "xoxb-test",
+		SlackAPIURL:           server.APIURL(),
+		MutexTableName:        "HitterMutexTable",
+		FunctionName:          "hitter-bot",
+		QueueMode:             lambdaQueueMode,
+	}
+	envconf = env
+	aws := newMemoryAWS()
+
+	saved := *backends
+	t.Cleanup(func() { *backends = saved })
+	backends.env = func() (*envConfig, error) {
+		return env, nil
+	}
+	backends.slack = func(env *envConfig) *slackClient {
+		return newSlackClient(env.SlackOAuthAccessToken, env.SlackAPIURL)
+	}
+	backends.aws = func(*envConfig) (awsServices, error) {
+		return aws, nil
+	}
+
+	return env, aws
+}
+
+func TestHandleEventRetry(t *testing.T) {
+	const event = `{"type":"event_callback","event_id":"Ev0001","event":{"type":"app_mention","text":"<@UHITTERBOT> hit","user":"U0001","channel":"C0123","ts":"1595673533.002100","event_ts":"1595673533.002100"}}`
+
+	tests := []struct {
+		name   string
+		reason string
+		num    int
+		// The status of the first attempt, or empty if it did not reach the bot
+		previous     string
+		wantBody     string
+		wantNoRetry  bool
+		wantEnqueued bool
+	}{
+		{name: "first attempt", wantBody: "accepted", wantEnqueued: true},
+		{name: "http_timeout does not rerun the running event", reason: "http_timeout", num: 1, previous: storage.MutexRunning, wantBody: "Already running", wantNoRetry: true},
+		{name: "http_timeout replays the succeeded event", reason: "http_timeout", num: 1, previous: storage.MutexSucceeded, wantBody: "stored result", wantNoRetry: true},
+		{name: "http_timeout reruns the failed event", reason: "http_timeout", num: 1, previous: storage.MutexFailed, wantBody: "accepted", wantEnqueued: true},
+		{name: "http_timeout runs the event that did not reach the bot", reason: "http_timeout", num: 1, wantBody: "accepted", wantEnqueued: true},
+		{name: "http_error reruns the failed event", reason: "http_error", num: 1, previous: storage.MutexFailed, wantBody: "accepted", wantEnqueued: true},
+		{name: "http_error does not rerun the running event", reason: "http_error", num: 1, previous: storage.MutexRunning, wantBody: "Already running", wantNoRetry: true},
+		{name: "http_error replays the succeeded event", reason: "http_error", num: 2, previous: storage.MutexSucceeded, wantBody: "stored result", wantNoRetry: true},
+		{name: "connection_failed replays the succeeded event", reason: "connection_failed", num: 1, previous: storage.MutexSucceeded, wantBody: "stored result", wantNoRetry: true},
+		{name: "connection_failed does not rerun the failed event", reason: "connection_failed", num: 1, previous: storage.MutexFailed, wantBody: "stored result", wantNoRetry: true},
+		{name: "connection_failed runs the event that did not reach the bot", reason: "connection_failed", num: 1, wantBody: "accepted", wantEnqueued: true},
+		{name: "unknown reason replays the succeeded event", reason: "new_reason", num: 1, previous: storage.MutexSucceeded, wantBody: "stored result", wantNoRetry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, aws := setupRetryTest(t)
+			ctx := context.Background()
+
+			if tt.previous != "" {
+				if _, _, err := aws.acquireMutexItem(ctx, env.MutexTableName, "Ev0001"); err != nil {
+					t.Fatal(err)
+				}
+				if tt.previous != storage.MutexRunning {
+					if err := aws.completeMutexItem(ctx, env.MutexTableName, "Ev0001", tt.previous, "stored result"); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			res, err := handleEvent(ctx, env, event, newRetryInfo(ctx, tt.num, tt.reason))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != 200 || !strings.Contains(res.Body, tt.wantBody) {
+				t.Errorf("got %d %s, want %q", res.StatusCode, res.Body, tt.wantBody)
+			}
+			if got := res.Headers[slackNoRetryHeader] == "1"; got != tt.wantNoRetry {
+				t.Errorf("got headers %v, want %s %v", res.Headers, slackNoRetryHeader, tt.wantNoRetry)
+			}
+			if got := len(aws.payloads[env.FunctionName]) > 0; got != tt.wantEnqueued {
+				t.Errorf("enqueued %v, want %v", got, tt.wantEnqueued)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"strings"
+)
+
+// The elements of rich_text blocks are nested, such as sections in lists.
+// https://api.slack.com/reference/block-kit/blocks#rich_text
+type richTextElement struct {
+	Type        string            `json:"type"`
+	Text        string            `json:"text"`
+	UserID      string            `json:"user_id"`
+	UsergroupID string            `json:"usergroup_id"`
+	ChannelID   string            `json:"channel_id"`
+	URL         string            `json:"url"`
+	Name        string            `json:"name"`
+	Range       string            `json:"range"`
+	Elements    []richTextElement `json:"elements"`
+}
+
+// Build the command text from the rich_text blocks.
+// Mentions, user groups, channels and links are written in the slack markup,
+// so that the tokenizer can give them a type regardless of the length of the IDs.
+func (se *slackEvent) commandText(ctx context.Context) string {
+	var b strings.Builder
+	for _, block := range se.Event.Blocks {
+		if block.Type != "rich_text" {
+			continue
+		}
+		writeRichText(ctx, &b, block.Elements)
+	}
+
+	// Fall back to the plain text if there are no rich_text blocks
+	if strings.TrimSpace(b.String()) == "" {
+		return se.Event.Text
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("commandText: %+v\n", b.String())
+
+	return b.String()
+}
+
+func writeRichText(ctx context.Context, b *strings.Builder, elements []richTextElement) {
+	for _, e := range elements {
+		switch e.Type {
+		case "rich_text_section", "rich_text_preformatted", "rich_text_quote":
+			writeRichText(ctx, b, e.Elements)
+			b.WriteString("\n")
+		case "rich_text_list":
+			writeRichText(ctx, b, e.Elements)
+		case "text":
+			b.WriteString(escapeSlackText(e.Text))
+		case "user":
+			b.WriteString("<@" + e.UserID + ">")
+		case "usergroup":
+			b.WriteString("<!subteam^" + e.UsergroupID + ">")
+		case "channel":
+			b.WriteString("<#" + e.ChannelID + ">")
+		case "link":
+			b.WriteString("<" + escapeSlackText(e.URL) + ">")
+		case "emoji":
+			b.WriteString(":" + e.Name + ":")
+		case "broadcast":
+			b.WriteString("<!" + e.Range + ">")
+		default:
+			// Output debug log
+			logger(ctx).Debugf("unknown element: %+v\n", e)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The events in testdata/rich_text are app_mention events as sent by slack
+func loadRichTextEvent(t *testing.T, name string) *slackEvent {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "rich_text", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	se := &slackEvent{}
+	if err := json.Unmarshal(b, se); err != nil {
+		t.Fatal(err)
+	}
+
+	return se
+}
+
+func TestRichTextCommand(t *testing.T) {
+	tests := []struct {
+		file        string
+		wantText    string
+		wantTo      string
+		wantCommand string
+		wantTokens  []string
+		// Bound by the declaration of the command, unless nil
+		wantArguments map[string]string
+		wantOptions   map[string][]string
+	}{
+		{
+			file:          "enterprise_users.json",
+			wantText:      "<@W017HPXHDF0> hit 2 --ex <@W018217962V> --ex=<@U0123456789AB>\n",
+			wantTo:        "W017HPXHDF0",
+			wantCommand:   "hit",
+			wantTokens:    []string{"text:2", "text:--ex", "user:W018217962V", "text:--ex=<@U0123456789AB>"},
+			wantArguments: map[string]string{"number": "2"},
+			wantOptions:   map[string][]string{"--ex": {"W018217962V", "U0123456789AB"}, "--window": {"10"}},
+		},
+		{
+			file:        "usergroup_channel.json",
+			wantText:    "<@U0HITTERBOT1> help <!subteam^S0123ABCDEF> <#C0123456789AB> <!here>\n",
+			wantTo:      "U0HITTERBOT1",
+			wantCommand: "help",
+			wantTokens:  []string{"usergroup:S0123ABCDEF", "channel:C0123456789AB", "text:@here"},
+		},
+		{
+			file:          "link.json",
+			wantText:      "<@U0HITTERBOT1> short <https://example.com/a?b=c&amp;d=e> --ttl=7\n",
+			wantTo:        "U0HITTERBOT1",
+			wantCommand:   "short",
+			wantTokens:    []string{"link:https://example.com/a?b=c&d=e", "text:--ttl=7"},
+			wantArguments: map[string]string{"url": "https://example.com/a?b=c&d=e"},
+			wantOptions:   map[string][]string{"--ttl": {"7"}},
+		},
+		{
+			file:          "nested_text.json",
+			wantText:      "<@U0HITTERBOT1> translate \"a &lt; b &amp; c\"\nfirst :tada:\nsecond\ncode\nquoted\n",
+			wantTo:        "U0HITTERBOT1",
+			wantCommand:   "translate",
+			wantTokens:    []string{"quoted:a < b & c", "text:first", "text::tada:", "text:second", "text:code", "text:quoted"},
+			wantArguments: map[string]string{"text": "\"a < b & c\"\nfirst :tada:\nsecond\ncode\nquoted"},
+			wantOptions:   map[string][]string{},
+		},
+		{
+			file:          "plain_text.json",
+			wantText:      "<@U0HITTERBOT1> hit 1 --ex <@W018217962V>",
+			wantTo:        "U0HITTERBOT1",
+			wantCommand:   "hit",
+			wantTokens:    []string{"text:1", "text:--ex", "user:W018217962V"},
+			wantArguments: map[string]string{"number": "1"},
+			wantOptions:   map[string][]string{"--ex": {"W018217962V"}, "--window": {"10"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			se := loadRichTextEvent(t, tt.file)
+			if got := se.commandText(context.Background()); got != tt.wantText {
+				t.Errorf("commandText() = %q, want %q", got, tt.wantText)
+			}
+
+			c := parseCommand(context.Background(), se)
+			if c.to != tt.wantTo || c.command != tt.wantCommand {
+				t.Errorf("to, command = %q, %q, want %q, %q", c.to, c.command, tt.wantTo, tt.wantCommand)
+			}
+
+			tokens, err := tokenize(c.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tokenStrings(tokens); !reflect.DeepEqual(got, tt.wantTokens) {
+				t.Errorf("tokens = %q, want %q", got, tt.wantTokens)
+			}
+
+			if tt.wantArguments == nil {
+				return
+			}
+			spec, ok := registry.lookup(c.command)
+			if !ok {
+				t.Fatalf("unknown command: %s", c.command)
+			}
+			if err := spec.bind(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.arguments, tt.wantArguments) {
+				t.Errorf("arguments = %v, want %v", c.arguments, tt.wantArguments)
+			}
+			if !reflect.DeepEqual(c.options, tt.wantOptions) {
+				t.Errorf("options = %v, want %v", c.options, tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestRichTextUnknownElement(t *testing.T) {
+	se := &slackEvent{}
+	err := json.Unmarshal([]byte(`{"event":{"text":"<@U0001> hit","blocks":[{"type":"rich_text","elements":[
+		{"type":"rich_text_section","elements":[{"type":"user","user_id":"U0001"},{"type":"text","text":" hit "},{"type":"date","timestamp":1595673533}]}
+	]}]}}`), se)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown elements are skipped
+	if got, want := se.commandText(context.Background()), "<@U0001> hit \n"; got != want {
+		t.Errorf("commandText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// The commands only depend on these narrow interfaces, so that they can be run
+// with the in-memory fakes in fakes.go and fakes_test.go instead of slack and AWS.
+// Every call takes the context of the command, which carries its deadline.
+
+// Messages and files in the conversation
+type chatPoster interface {
+	postMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error)
+	uploadFile(ctx context.Context, channel string, body []byte, filename string, comment string, ts string) error
+	downloadFile(ctx context.Context, url string) ([]byte, error)
+	// Open the direct message with the users, or the group DM if there are several
+	openConversation(ctx context.Context, users ...string) (string, error)
+}
+
+// Members of the channels
+type memberDirectory interface {
+	getUsers(ctx context.Context, channelID string, limit int) ([]string, error)
+	// Separate the IDs into people and bots
+	classifyUsers(ctx context.Context, ids ...string) ([]string, []string, error)
+}
+
+// Files shared with a pre-signed URL
+type fileStore interface {
+	uploadAndPreSignedURL(ctx context.Context, bucket string, key string, body []byte, min int) (*storage.S3Item, error)
+}
+
+// Destinations of the shortened URLs
+type urlStore interface {
+	putURLItem(ctx context.Context, tableName string, id string, urlStr string, days int) (int64, error)
+	getURLItem(ctx context.Context, tableName string, id string) (*storage.URLItem, error)
+}
+
+// Prevents the same event from being processed multiple times
+type idempotencyStore interface {
+	acquireMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, bool, error)
+	restartMutexItem(ctx context.Context, tableName string, id string) (bool, error)
+	completeMutexItem(ctx context.Context, tableName string, id string, status string, result string) error
+	getMutexItem(ctx context.Context, tableName string, id string) (*storage.MutexItem, error)
+}
+
+// State of the commands in each channel, such as the history of hit
+type stateStore interface {
+	getStateItem(ctx context.Context, tableName string, id string) (*storage.StateItem, error)
+	// Fails with storage.ErrStateConflict if the item was updated after it was read
+	putStateItem(ctx context.Context, tableName string, item *storage.StateItem, days int) error
+}
+
+type translator interface {
+	translate(ctx context.Context, text string, source string, target string) (string, error)
+}
+
+type languageDetector interface {
+	detectLanguageCode(ctx context.Context, text string) (string, error)
+}
+
+// Runs the worker asynchronously
+type functionInvoker interface {
+	invokeAsync(ctx context.Context, functionName string, payload []byte) error
+}
+
+// Everything provided by awsClient
+type awsServices interface {
+	fileStore
+	urlStore
+	idempotencyStore
+	stateStore
+	translator
+	languageDetector
+	functionInvoker
+}
@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+)
+
+// Verifying requests from Slack
+// https://api.slack.com/authentication/verifying-requests-from-slack
+const (
+	slackSignatureHeader  = "X-Slack-Signature"
+	slackTimestampHeader  = "X-Slack-Request-Timestamp"
+	slackSignatureVersion = "v0"
+)
+
+type signatureVerifier struct {
+	secret string
+	// Accepted as well until the expiry, or without a limit if the expiry is zero
+	previous       string
+	previousExpiry time.Time
+	maxAge         time.Duration
+	now            func() time.Time
+}
+
+func newSignatureVerifier(ctx context.Context, env *envConfig) *signatureVerifier {
+	sv := &signatureVerifier{}
+	sv.secret = env.SlackSigningSecret
+	sv.maxAge = time.Duration(env.SlackSignatureMaxAge) * time.Second
+	sv.now = time.Now
+
+	// During the rotation period, the previous secret is also accepted.
+	// The expiry is read as JST unless it has a time zone.
+	if env.SlackPreviousSigningSecret != "" {
+		if env.SlackPreviousSigningSecretExpiry == "" {
+			sv.previous = env.SlackPreviousSigningSecret
+		} else {
+			expiry, err := datetime.ParseInJST(env.SlackPreviousSigningSecretExpiry)
+			if err != nil {
+				logger(ctx).Println("[ERROR] Failed to parse the expiry of the previous signing secret: ", err)
+			} else {
+				sv.previous = env.SlackPreviousSigningSecret
+				sv.previousExpiry = expiry
+			}
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("previous: %+v expiry: %+v\n", sv.previous != "", sv.previousExpiry)
+	logger(ctx).Debugf("maxAge: %+v\n", sv.maxAge)
+
+	return sv
+}
+
+func (v *signatureVerifier) verify(ctx context.Context, headers map[string]string, body string) error {
+	// Anyone could sign the request with an empty secret
+	if v.secret == "" {
+		return errors.New("The slack signing secret is not configured")
+	}
+
+	signature := getHeader(headers, slackSignatureHeader)
+	timestamp := getHeader(headers, slackTimestampHeader)
+
+	// Output debug log
+	logger(ctx).Debugf("signature: %+v\n", signature)
+	logger(ctx).Debugf("timestamp: %+v\n", timestamp)
+
+	if signature == "" || timestamp == "" {
+		return errors.New("The request does not have a slack signature")
+	}
+
+	// Reject requests outside the time window to prevent replay attacks
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("The request timestamp is invalid: %s", timestamp)
+	}
+	age := v.now().Sub(time.Unix(sec, 0))
+	if age < 0 {
+		age = -age
+	}
+	if v.maxAge > 0 && age > v.maxAge {
+		return fmt.Errorf("The request timestamp is out of the allowed window: %s", age)
+	}
+
+	// The signature is "v0=" followed by the hex encoded HMAC-SHA256
+	prefix := slackSignatureVersion + "="
+	if !strings.HasPrefix(signature, prefix) {
+		return fmt.Errorf("The signature version is not supported: %s", signature)
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return fmt.Errorf("The signature is not hex encoded: %s", signature)
+	}
+
+	// Accept the request if any of the secrets match
+	secrets := []string{v.secret}
+	if v.previous != "" && (v.previousExpiry.IsZero() || v.now().Before(v.previousExpiry)) {
+		secrets = append(secrets, v.previous)
+	}
+	base := slackSignatureVersion + ":" + timestamp + ":" + body
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(base))
+		if hmac.Equal(received, mac.Sum(nil)) {
+			return nil
+		}
+	}
+
+	return errors.New("The signature does not match the signing secret")
+}
+
+func getHeader(headers map[string]string, key string) string {
+	// Header names passed by API Gateway keep the case sent by the client
+	if v, ok := headers[key]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signRequest(secret string, timestamp int64, body string) map[string]string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	return map[string]string{
+		"x-slack-signature":         "v0=" + hex.EncodeToString(mac.Sum(nil)),
+		"X-Slack-Request-Timestamp": ts,
+	}
+}
+
+func TestSignatureVerifier(t *testing.T) {
+	// 2020-10-31 00:00:00 JST
+	expiry := time.Date(2020, 10, 30, 15, 0, 0, 0, time.UTC)
+	before := expiry.Add(-time.Minute)
+	after := expiry.Add(time.Minute)
+	body := `{"type":"event_callback"}`
+
+	tests := []struct {
+		name    string
+		env     envConfig
+		now     time.Time
+		headers map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("new", before.Unix(), body),
+		},
+		{
+			name:    "within max age",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("new", before.Add(-5*time.Minute).Unix(), body),
+		},
+		{
+			name:    "older than max age",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("new", before.Add(-5*time.Minute-time.Second).Unix(), body),
+			wantErr: true,
+		},
+		{
+			name:    "newer than max age",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("new", before.Add(5*time.Minute+time.Second).Unix(), body),
+			wantErr: true,
+		},
+		{
+			name:    "wrong secret",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("other", before.Unix(), body),
+			wantErr: true,
+		},
+		{
+			name:    "malformed hex",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: map[string]string{"X-Slack-Signature": "v0=zz", "X-Slack-Request-Timestamp": strconv.FormatInt(before.Unix(), 10)},
+			wantErr: true,
+		},
+		{
+			name: "unsupported version",
+			env:  envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:  before,
+			headers: func() map[string]string {
+				h := signRequest("new", before.Unix(), body)
+				h["x-slack-signature"] = "v1=" + h["x-slack-signature"][3:]
+				return h
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "malformed timestamp",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: map[string]string{"X-Slack-Signature": "v0=00", "X-Slack-Request-Timestamp": "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "no signature",
+			env:     envConfig{SlackSigningSecret: "new", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "previous secret before expiry",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackPreviousSigningSecretExpiry: "2020-10-31 00:00:00", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("old", before.Unix(), body),
+		},
+		{
+			name:    "previous secret after expiry",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackPreviousSigningSecretExpiry: "2020-10-31 00:00:00", SlackSignatureMaxAge: 300},
+			now:     after,
+			headers: signRequest("old", after.Unix(), body),
+			wantErr: true,
+		},
+		{
+			name:    "new secret after expiry",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackPreviousSigningSecretExpiry: "2020-10-31 00:00:00", SlackSignatureMaxAge: 300},
+			now:     after,
+			headers: signRequest("new", after.Unix(), body),
+		},
+		{
+			name:    "previous secret with an expiry in another time zone",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackPreviousSigningSecretExpiry: "2020-10-31 00:00:00 +0000", SlackSignatureMaxAge: 300},
+			now:     after,
+			headers: signRequest("old", after.Unix(), body),
+		},
+		{
+			name:    "previous secret without expiry",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackSignatureMaxAge: 300},
+			now:     after,
+			headers: signRequest("old", after.Unix(), body),
+		},
+		{
+			name:    "previous secret with an invalid expiry",
+			env:     envConfig{SlackSigningSecret: "new", SlackPreviousSigningSecret: "old", SlackPreviousSigningSecretExpiry: "someday", SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("old", before.Unix(), body),
+			wantErr: true,
+		},
+		{
+			name:    "empty secret",
+			env:     envConfig{SlackSignatureMaxAge: 300},
+			now:     before,
+			headers: signRequest("", before.Unix(), body),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := newSignatureVerifier(context.Background(), &tt.env)
+			sv.now = func() time.Time { return tt.now }
+
+			err := sv.verify(context.Background(), tt.headers, body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verify() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+type slackClient struct {
+	chat    chatPoster
+	members memberDirectory
+	// The last result notified
+	result *CommandResult
+}
+
+// The slack Web API, implementing chatPoster and memberDirectory
+type slackAPI struct {
+	client *slack.Client
+}
+
+// automatically generated using the following
+// https://mholt.github.io/json-to-go/
+type slackEvent struct {
+	// only url_verification event
+	// https://api.slack.com/events/url_verification
+	Challenge string `json:"challenge"`
+
+	Token        string `json:"token"`
+	TeamID       string `json:"team_id"`
+	EnterpriseID string `json:"enterprise_id"`
+	APIAppID     string `json:"api_app_id"`
+
+	Event struct {
+		ClientMsgID  string `json:"client_msg_id"`
+		Type         string `json:"type"`
+		Text         string `json:"text"`
+		User         string `json:"user"`
+		Ts           string `json:"ts"`
+		Team         string `json:"team"`
+		DisplayAsBot bool   `json:"display_as_bot"`
+		Channel      string `json:"channel"`
+		EventTs      string `json:"event_ts"`
+		Upload       bool   `json:"upload"`
+		Subtype      string `json:"subtype"`
+		Username     string `json:"username"`
+		BotID        string `json:"bot_id"`
+
+		Files []struct {
+			ID                 string `json:"id"`
+			Created            int    `json:"created"`
+			Timestamp          int    `json:"timestamp"`
+			Name               string `json:"name"`
+			Title              string `json:"title"`
+			Mimetype           string `json:"mimetype"`
+			Filetype           string `json:"filetype"`
+			PrettyType         string `json:"pretty_type"`
+			User               string `json:"user"`
+			Editable           bool   `json:"editable"`
+			Size               int    `json:"size"`
+			Mode               string `json:"mode"`
+			IsExternal         bool   `json:"is_external"`
+			ExternalType       string `json:"external_type"`
+			IsPublic           bool   `json:"is_public"`
+			PublicURLShared    bool   `json:"public_url_shared"`
+			DisplayAsBot       bool   `json:"display_as_bot"`
+			Username           string `json:"username"`
+			URLPrivate         string `json:"url_private"`
+			URLPrivateDownload string `json:"url_private_download"`
+			Thumb64            string `json:"thumb_64"`
+			Thumb80            string `json:"thumb_80"`
+			Thumb360           string `json:"thumb_360"`
+			Thumb360W          int    `json:"thumb_360_w"`
+			Thumb360H          int    `json:"thumb_360_h"`
+			Thumb480           string `json:"thumb_480"`
+			Thumb480W          int    `json:"thumb_480_w"`
+			Thumb480H          int    `json:"thumb_480_h"`
+			Thumb160           string `json:"thumb_160"`
+			OriginalW          int    `json:"original_w"`
+			OriginalH          int    `json:"original_h"`
+			ThumbTiny          string `json:"thumb_tiny"`
+			Permalink          string `json:"permalink"`
+			PermalinkPublic    string `json:"permalink_public"`
+			IsStarred          bool   `json:"is_starred"`
+			HasRichPreview     bool   `json:"has_rich_preview"`
+		} `json:"files"`
+
+		Blocks []struct {
+			Type     string            `json:"type"`
+			BlockID  string            `json:"block_id"`
+			Elements []richTextElement `json:"elements"`
+		} `json:"blocks"`
+	} `json:"event"`
+
+	Type        string   `json:"type"`
+	EventID     string   `json:"event_id"`
+	EventTime   int      `json:"event_time"`
+	AuthedUsers []string `json:"authed_users"`
+}
+
+// https://api.slack.com/events/url_verification
+/*
+{
+    "token": "Jhj5dZrVaK7ZwHHjRyZWjbDl",
+    "challenge": "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P",
+    "type": "url_verification"
+}
+*/
+
+// https://api.slack.com/events-api#receiving_events
+// https://api.slack.com/types/event
+// https://api.slack.com/events/app_mention
+/*
+{
+    "token": "ZZZZZZWSxiZZZ2yIvs3peJ",
+    "team_id": "T061EG9R6",
+    "api_app_id": "A0MDYCDME",
+    "event": {
+        "type": "app_mention",
+        "user": "U061F7AUR",
+        "text": "What is the hour of the pearl, <@U0LAN0Z89>?",
+        "ts": "1515449522.000016",
+        "channel": "C0LAN2Q65",
+        "event_ts": "1515449522000016"
+    },
+    "type": "event_callback",
+    "event_id": "Ev0LAN670R",
+    "event_time": 1515449522000016,
+    "authed_users": [
+        "U0LAN0Z89"
+    ]
+}
+*/
+
+func newSlackClient(token string, apiURL string, options ...slack.Option) *slackClient {
+	api := newSlackAPI(token, apiURL, options...)
+
+	return newSlackClientWith(api, api)
+}
+
+func newSlackAPI(token string, apiURL string, options ...slack.Option) *slackAPI {
+	// The API URL is only changed to use a fake server in tests
+	if apiURL != "" {
+		options = append(options, slack.OptionAPIURL(apiURL))
+	}
+
+	return &slackAPI{client: slack.New(token, options...)}
+}
+
+func newSlackClientWith(chat chatPoster, members memberDirectory) *slackClient {
+	sc := &slackClient{}
+	sc.chat = chat
+	sc.members = members
+
+	return sc
+}
+
+// Fields added to the log while the event is handled
+func (se *slackEvent) logFields() logging.Fields {
+	return logging.Fields{"event_id": se.EventID, "team": se.TeamID, "channel": se.Event.Channel, "user": se.Event.User}
+}
+
+func (c *slackClient) parseEvent(ctx context.Context, eventJSON string) (*slackEvent, string, error) {
+	text := ""
+	se := &slackEvent{}
+
+	// Output debug log
+	logger(ctx).Debugf("eventJSON: %+v\n", eventJSON)
+
+	err := json.Unmarshal([]byte(eventJSON), se)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to parse the slack event JSON.: ", err)
+		return se, text, err
+	}
+
+	// Use the parsed information to perform a check.
+	// The request itself has already been verified with the slack signing secret.
+
+	// Accept slack url_verification event
+	// https://api.slack.com/events/url_verification
+	if se.Type == "url_verification" {
+		logger(ctx).Println("[ACCEPTED] Slack url_verification event")
+		text = fmt.Sprintf(`{"challenge": %s}`, se.Challenge)
+		return se, text, err
+	}
+
+	// Respond only to specific events
+	if se.Event.Type != "app_mention" {
+		logger(ctx).Println("[REJECTED] Slack event type do not 'app_mention': ", se.Event.Type)
+		text = `{"message": "[REJECTED] Slack event type do not 'app_mention'"}`
+		return se, text, err
+	}
+
+	// filter the channel?
+	if envconf.SlackChannelID != "" {
+		if envconf.SlackChannelID != se.Event.Channel {
+			logger(ctx).Println("[REJECTED] Slack channel ID do not match: ", se.Event.Channel)
+			text = `{"message": "[REJECTED] Slack channel ID do not match"}`
+			return se, text, err
+		}
+	}
+
+	return se, text, err
+}
+
+func (c *slackClient) getTargetUsers(ctx context.Context, channelID string, exclusions []string) ([]string, error) {
+	// Get a list of users who have joined the channel
+	users, err := c.members.getUsers(ctx, channelID, 1000)
+	if err != nil {
+		return nil, err
+	}
+
+	// Separate the user list into bots and people.
+	userIds, _, err := c.members.classifyUsers(ctx, users...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do you have a list of users not eligible for the lottery?
+	choices := excludeUsers(userIds, exclusions)
+
+	// Output debug log
+	logger(ctx).Debugf("exclusions: %+v\n", exclusions)
+	logger(ctx).Debugf("choices: %+v\n", choices)
+
+	return choices, nil
+}
+
+func excludeUsers(users []string, exclusions []string) []string {
+	choices := append([]string{}, users...)
+	for _, x := range exclusions {
+		ret := make([]string, len(choices))
+		i := 0
+		for _, t := range choices {
+			if x != t {
+				ret[i] = t
+				i++
+			}
+		}
+		choices = ret[:i]
+	}
+
+	return choices
+}
+
+func (c *slackAPI) postMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+
+	return c.client.PostMessageContext(ctx, channel, options...)
+}
+
+func (c *slackAPI) openConversation(ctx context.Context, users ...string) (string, error) {
+	params := &slack.OpenConversationParameters{Users: users}
+
+	// Opening a group DM
+	// https://api.slack.com/methods/conversations.open
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	ch, _, _, err := c.client.OpenConversationContext(ctx, params)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to open the conversation: ", users, err)
+		return "", err
+	}
+
+	return ch.ID, nil
+}
+
+func (c *slackAPI) uploadFile(ctx context.Context, channel string, body []byte, filename string, comment string, ts string) error {
+	params := slack.FileUploadParameters{}
+	params.Reader = bytes.NewReader(body)
+	params.Channels = []string{channel}
+	params.Filetype = "text"
+	params.Filename = filename
+	params.InitialComment = comment
+	params.ThreadTimestamp = ts
+
+	// Output debug log
+	logger(ctx).Debugf("params: %+v\n", params)
+
+	// Uploading files to a thread
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	f, err := c.client.UploadFileContext(ctx, params)
+
+	// Output debug log
+	logger(ctx).Debugf("file: %+v\n", f)
+
+	return err
+}
+
+func (c *slackAPI) downloadFile(ctx context.Context, url string) ([]byte, error) {
+	var wb bytes.Buffer
+
+	// Downloading files from slack
+	// GetFile does not take a context, so stop waiting for it when the command is cancelled
+	done := make(chan error, 1)
+	go func() {
+		done <- c.client.GetFile(url, &wb)
+	}()
+
+	select {
+	case err := <-done:
+		return wb.Bytes(), err
+	case <-ctx.Done():
+		logger(ctx).Println("[ERROR] Stopped downloading the file: ", ctx.Err())
+		return nil, ctx.Err()
+	}
+}
+
+func (c *slackAPI) getUsers(ctx context.Context, channelID string, limit int) ([]string, error) {
+	// Get all users involved in the conversation
+	// https://api.slack.com/methods/conversations.members
+	param := &slack.GetUsersInConversationParameters{}
+	param.ChannelID = channelID
+	param.Cursor = ""
+	// If the limit is greater than zero, set it.
+	if limit > 0 {
+		param.Limit = limit
+	}
+
+	var users []string
+	for {
+		callCtx, cancel := callContext(ctx)
+		list, next, err := c.client.GetUsersInConversationContext(callCtx, param)
+		cancel()
+
+		// Output debug log
+		logger(ctx).Debugf("list: %+v\n", list)
+		logger(ctx).Debugf("next: %+v\n", next)
+		logger(ctx).Debugf("err: %+v\n", err)
+		logger(ctx).Debugf("users: %+v\n", users)
+
+		if err != nil {
+			logger(ctx).Println("[ERROR] Failed to retrieve the user list: ", err)
+			return users, err
+		}
+
+		users = append(users, list...)
+
+		if next == "" {
+			break
+		}
+
+		param.Cursor = next
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("users: %+v\n", users)
+
+	return users, nil
+}
+
+func (c *slackAPI) classifyUsers(ctx context.Context, ids ...string) ([]string, []string, error) {
+	var botIds []string
+	var userIds []string
+
+	// Retrieving User Information from a User ID
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	list, err := c.client.GetUsersInfoContext(ctx, ids...)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to retrieve user information: ", err)
+		return userIds, botIds, err
+	}
+
+	// Classify Bot and User IDs
+	for _, item := range *list {
+		if item.IsBot {
+			botIds = append(botIds, item.ID)
+		} else {
+			userIds = append(userIds, item.ID)
+		}
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("botIds: %+v\n", botIds)
+	logger(ctx).Debugf("userIds: %+v\n", userIds)
+
+	return userIds, botIds, nil
+}
+
+func (c *slackClient) notifyMessage(ctx context.Context, cp *commandParameter, option slack.MsgOption) (string, string, error) {
+	options := []slack.MsgOption{option}
+
+	// Slash commands are answered to the response URL with the specified visibility
+	if cp.responseURL != "" {
+		options = append(options, slack.MsgOptionResponseURL(cp.responseURL, cp.responseType))
+	} else if cp.isEphemeral() {
+		options = append(options, slack.MsgOptionPostEphemeral(cp.from))
+	}
+
+	// Sending a message to slack
+	// https://api.slack.com/methods/chat.postMessage
+	channelID, timestamp, err := c.chat.postMessage(ctx, cp.channel, options...)
+	if err != nil {
+		logger(ctx).Println("[ERROR] The notification to slack failed.: ", channelID, timestamp, err)
+		return "", "", err
+	}
+
+	return channelID, timestamp, err
+}
+
+// Post the message, and attach the result file to its thread if there is one
+func (c *slackClient) notify(ctx context.Context, cp *commandParameter, n *notification) error {
+	ch, ts, err := c.notifyMessage(ctx, cp, slack.MsgOptionBlocks(n.Blocks...))
+	if err != nil || n.File == nil {
+		return err
+	}
+
+	// Organize file names
+	dateStr, _ := datetime.FileNameDateString(strings.Replace(ts, ".", "", -1))
+
+	return c.notifyResultFile(ctx, cp, ch, ts, n.File, dateStr+"_"+n.File.Name)
+}
+
+func (c *slackClient) notifyResultFile(ctx context.Context, cp *commandParameter, channel string, ts string, file *resultFile, filename string) error {
+	if cp.responseURL != "" || cp.isEphemeral() {
+		_, _, err := c.notifyMessage(ctx, cp, slack.MsgOptionBlocks(buildResultFileMessage(file).Blocks...))
+		return err
+	}
+
+	// Return results in an attachment, taking into account the character limit.
+	return c.chat.uploadFile(ctx, channel, []byte(file.Body), filename, file.Comment, ts)
+}
+
+func (c *slackClient) notifyError(ctx context.Context, cp *commandParameter, message string) error {
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildErrorMessage(cp, message))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of a command execution error.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyHelpSuccess(ctx context.Context, cp *commandParameter, text string) error {
+	c.record(cp, &helpResult{Text: text})
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildHelpMessage(cp, text))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the help command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyHitSuccess(ctx context.Context, cp *commandParameter, result []string) error {
+	c.record(cp, &hitResult{Users: result})
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildHitMessage(cp, result))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the hit command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyOrderSuccess(ctx context.Context, cp *commandParameter, users []string, starts []string) error {
+	c.record(cp, newOrderResult(users, starts))
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildOrderMessage(cp, users, starts))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the order command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyPairSuccess(ctx context.Context, cp *commandParameter, groups [][]string, repeated int, failed [][]string) error {
+	c.record(cp, &pairResult{Groups: groups, Repeated: repeated, Failed: failed})
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildPairMessage(cp, groups, repeated, failed))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the pair command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyTeamsSuccess(ctx context.Context, cp *commandParameter, teams [][]string) error {
+	c.record(cp, &teamsResult{Teams: teams})
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildTeamsMessage(cp, teams))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the teams command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyRotationSuccess(ctx context.Context, cp *commandParameter, name string, state *rotationState) error {
+	c.record(cp, newRotationResult(name, state))
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildRotationMessage(cp, name, state.Order, state.Next))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the rotation command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyTranslateSuccess(ctx context.Context, cp *commandParameter, source string, translated string, sourceLangCode string, translatedLangCode string) error {
+	c.record(cp, &translateResult{Source: source, SourceLanguage: sourceLangCode, Text: translated, Language: translatedLangCode})
+
+	// Notify your slack of the results, taking into account the character limit.
+	err := c.notify(ctx, cp, buildTranslateMessage(cp, source, translated, sourceLangCode, translatedLangCode))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify and upload file slack of the result of the translate command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyLinkSuccess(ctx context.Context, cp *commandParameter, results []*storage.S3Item) error {
+	c.record(cp, newLinkResults(results))
+
+	// Notify your slack of the results, taking into account the character limit.
+	err := c.notify(ctx, cp, buildLinkMessage(cp, results))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify and upload file slack of the result of the link command.")
+	}
+
+	return err
+}
+
+func (c *slackClient) notifyShortSuccess(ctx context.Context, cp *commandParameter, urlStr string, dateStr string) error {
+	c.record(cp, &shortResult{URL: urlStr, Expires: dateStr})
+
+	// Notify your slack of the results
+	err := c.notify(ctx, cp, buildShortMessage(cp, urlStr, dateStr))
+	if err == nil {
+		logger(ctx).Println("[NOTICE] Notify slack of the result of the short command.")
+	}
+
+	return err
+}
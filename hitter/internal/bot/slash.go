@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/slack-go/slack"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// Slash commands are sent as "application/x-www-form-urlencoded"
+// https://api.slack.com/interactivity/slash-commands
+const formContentType = "application/x-www-form-urlencoded"
+
+func isSlashCommandRequest(headers map[string]string) bool {
+	return strings.HasPrefix(getHeader(headers, "Content-Type"), formContentType)
+}
+
+func (c *slackClient) parseSlashCommand(ctx context.Context, body string) (*slack.SlashCommand, string, error) {
+	text := ""
+	s := &slack.SlashCommand{}
+
+	// Output debug log
+	logger(ctx).Debugf("body: %+v\n", body)
+
+	form, err := url.ParseQuery(body)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to parse the slash command.: ", err)
+		return s, text, err
+	}
+
+	s.TeamID = form.Get("team_id")
+	s.TeamDomain = form.Get("team_domain")
+	s.EnterpriseID = form.Get("enterprise_id")
+	s.ChannelID = form.Get("channel_id")
+	s.ChannelName = form.Get("channel_name")
+	s.UserID = form.Get("user_id")
+	s.UserName = form.Get("user_name")
+	s.Command = form.Get("command")
+	s.Text = form.Get("text")
+	s.ResponseURL = form.Get("response_url")
+	s.TriggerID = form.Get("trigger_id")
+	s.APIAppID = form.Get("api_app_id")
+
+	// The result is returned to the response URL
+	if s.ResponseURL == "" {
+		logger(ctx).Println("[REJECTED] The slash command does not have a response URL")
+		text = `{"response_type": "ephemeral", "text": "[REJECTED] The slash command does not have a response URL"}`
+		return s, text, err
+	}
+
+	// filter the channel?
+	if envconf.SlackChannelID != "" {
+		if envconf.SlackChannelID != s.ChannelID {
+			logger(ctx).Println("[REJECTED] Slack channel ID do not match: ", s.ChannelID)
+			text = `{"response_type": "ephemeral", "text": "[REJECTED] Slack channel ID do not match"}`
+			return s, text, err
+		}
+	}
+
+	return s, text, err
+}
+
+func handleSlashCommand(ctx context.Context, env *envConfig, body string) (events.APIGatewayProxyResponse, error) {
+	// Initialize the slack client
+	sc := backends.slack(env)
+
+	// Parsing the slash command sent from slack
+	s, result, err := sc.parseSlashCommand(ctx, body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+	if result != "" {
+		headers := map[string]string{"Content-Type": "application/json"}
+		return events.APIGatewayProxyResponse{Headers: headers, Body: result, StatusCode: 200}, nil
+	}
+	command, _ := splitHead(s.Text)
+	ctx = logging.NewContext(ctx, logging.Fields{"team": s.TeamID, "channel": s.ChannelID, "user": s.UserID, "command": command})
+
+	// Initialize the aws client
+	aws, err := backends.aws(env)
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	// Slack does not retry slash commands, so there is no need for the mutex table
+	queue, err := getJobQueue(ctx, env, aws)
+	if err == nil {
+		err = queue.enqueue(ctx, &commandJob{Kind: slashCommandJob, Body: body})
+	}
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to enqueue the command: ", err)
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
+
+	// Respond with an empty body, the result is returned to the response URL
+	return events.APIGatewayProxyResponse{Body: "", StatusCode: 200}, nil
+}
+
+func parseSlashCommandParameter(ctx context.Context, s *slack.SlashCommand, defaultResponseType string) *commandParameter {
+	cmdParam := &commandParameter{}
+	cmdParam.team = s.TeamID
+	cmdParam.channel = s.ChannelID
+	cmdParam.from = s.UserID
+	cmdParam.text = strings.TrimSpace(s.Command + " " + s.Text)
+	cmdParam.arguments = make(map[string]string)
+	cmdParam.options = make(map[string][]string)
+	cmdParam.files = make(map[string]string)
+	cmdParam.responseURL = s.ResponseURL
+	cmdParam.responseType = defaultResponseType
+
+	// Slash commands do not have an event timestamp, so use the time it was received
+	now := time.Now()
+	cmdParam.eventTs = fmt.Sprintf("%d.%06d", now.Unix(), now.Nanosecond()/1000)
+
+	// Parse Text
+	// /hitter hit 3 --ex <@W018217962V|userA>
+	// There is no mention to the bot, the text starts with the command.
+	cmdParam.command, cmdParam.body = splitHead(s.Text)
+
+	// Output debug log
+	logger(ctx).Debugf("cmdParam: %+v\n", cmdParam)
+
+	return cmdParam
+}
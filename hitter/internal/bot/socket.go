@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// RunSocketMode receives the requests from slack over a WebSocket connection,
+// so no public endpoint is needed. It returns when SIGINT or SIGTERM is received.
+// https://api.slack.com/apis/connections/socket
+func RunSocketMode() error {
+	// Load information from environment variables and make it available on a global basis
+	env, err := backends.env()
+	if err != nil {
+		return err
+	}
+	if env.SlackAppToken == "" {
+		return errors.New("The app-level token is required for Socket Mode")
+	}
+
+	options := []slack.Option{slack.OptionAppLevelToken(env.SlackAppToken)}
+	if env.SlackAPIURL != "" {
+		options = append(options, slack.OptionAPIURL(env.SlackAPIURL))
+	}
+	api := slack.New(env.SlackOAuthAccessToken, options...)
+	client := socketmode.New(api,
+		socketmode.OptionDebug(env.DebugLog),
+		socketmode.OptionLog(log.New(os.Stderr, "[SOCKET] ", log.LstdFlags)),
+	)
+
+	// Stop on SIGINT or SIGTERM
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		s := <-sig
+		log.Println("[SOCKET] Shutting down: ", s)
+		cancel()
+	}()
+
+	go func() {
+		for evt := range client.Events {
+			handleSocketEvent(ctx, client, env, evt)
+		}
+	}()
+
+	log.Println("[SOCKET] Connecting to slack with Socket Mode")
+	err = client.RunContext(ctx)
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+
+	// Wait for the running commands to finish
+	if sharedLocalQueue != nil {
+		sharedLocalQueue.close()
+	}
+
+	return err
+}
+
+func handleSocketEvent(ctx context.Context, client *socketmode.Client, env *envConfig, evt socketmode.Event) {
+	// Every line of the log carries the envelope ID of the request, as the request ID over HTTP
+	if evt.Request != nil {
+		ctx = logging.NewContext(ctx, logging.Fields{"envelope_id": evt.Request.EnvelopeID})
+	}
+
+	switch evt.Type {
+	case socketmode.EventTypeConnecting:
+		logger(ctx).Println("[SOCKET] Connecting to slack")
+	case socketmode.EventTypeConnectionError:
+		logger(ctx).Println("[SOCKET] Failed to connect to slack: ", evt.Data)
+	case socketmode.EventTypeConnected:
+		logger(ctx).Println("[SOCKET] Connected to slack")
+	case socketmode.EventTypeEventsAPI:
+		// Acknowledge first, as slack expects a response within 3 seconds
+		client.Ack(*evt.Request)
+
+		// The payload is the same JSON as the Events API
+		retry := newRetryInfo(ctx, evt.Request.RetryAttempt, evt.Request.RetryReason)
+		res, err := handleEvent(ctx, env, string(evt.Request.Payload), retry)
+		logSocketResponse(ctx, evt.Type, res, err)
+	case socketmode.EventTypeSlashCommand:
+		client.Ack(*evt.Request)
+
+		cmd, ok := evt.Data.(slack.SlashCommand)
+		if !ok {
+			logger(ctx).Println("[ERROR] Unexpected slash command: ", evt.Data)
+			return
+		}
+		res, err := handleSlashCommand(ctx, env, encodeSlashCommand(&cmd))
+		logSocketResponse(ctx, evt.Type, res, err)
+	case socketmode.EventTypeInteractive:
+		client.Ack(*evt.Request)
+
+		callback, ok := evt.Data.(slack.InteractionCallback)
+		if !ok {
+			logger(ctx).Println("[ERROR] Unexpected interaction: ", evt.Data)
+			return
+		}
+		res, err := handleInteraction(ctx, env, &callback, string(evt.Request.Payload))
+		logSocketResponse(ctx, evt.Type, res, err)
+	default:
+		// Output debug log
+		logger(ctx).Debugf("ignored socket mode event: %+v\n", evt.Type)
+	}
+}
+
+// The request has already been acknowledged, so slack does not see the failure and does not retry it.
+// It is written to the log instead, such as when the command could not be enqueued.
+func logSocketResponse(ctx context.Context, eventType socketmode.EventType, res events.APIGatewayProxyResponse, err error) {
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to handle the socket mode request: ", eventType, err)
+		return
+	}
+	if res.StatusCode >= 400 {
+		logger(ctx).Println("[ERROR] Failed to handle the socket mode request: ", eventType, res.StatusCode, res.Body)
+		return
+	}
+
+	// Output debug log
+	logger(ctx).Debugf("response: %+v\n", res)
+}
+
+// Encode the slash command in the same format as sent over HTTP
+func encodeSlashCommand(s *slack.SlashCommand) string {
+	form := url.Values{}
+	form.Set("team_id", s.TeamID)
+	form.Set("team_domain", s.TeamDomain)
+	form.Set("enterprise_id", s.EnterpriseID)
+	form.Set("channel_id", s.ChannelID)
+	form.Set("channel_name", s.ChannelName)
+	form.Set("user_id", s.UserID)
+	form.Set("user_name", s.UserName)
+	form.Set("command", s.Command)
+	form.Set("text", s.Text)
+	form.Set("response_url", s.ResponseURL)
+	form.Set("trigger_id", s.TriggerID)
+	form.Set("api_app_id", s.APIAppID)
+
+	return form.Encode()
+}
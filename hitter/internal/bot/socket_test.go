@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+func TestHandleSocketEvent(t *testing.T) {
+	const event = `{"type":"event_callback","event_id":"Ev0001","event":{"type":"app_mention","text":"<@UHITTERBOT> hit","user":"U0001","channel":"C0123","ts":"1595673533.002100","event_ts":"1595673533.002100"}}`
+	const interaction = `{"type":"message_action","callback_id":"translate","team":{"id":"T0001"},"channel":{"id":"C0123"},"user":{"id":"U0001"},"message":{"text":"hello"}}`
+
+	env, aws := setupRetryTest(t)
+	ctx := context.Background()
+
+	// The acknowledgements are buffered, as the client is not connected
+	client := socketmode.New(slack.New("xoxb-test", slack.OptionAppLevelToken("xapp-test")))
+
+	callback := slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(interaction), &callback); err != nil {
+		t.Fatal(err)
+	}
+	cmd := slack.SlashCommand{TeamID: "T0001", ChannelID: "C0123", UserID: "U0001", Command: "/hitter", Text: "hit 2 --ex <@U0003>", ResponseURL: "https://hooks.slack.com/commands/T0001/1"}
+
+	evts := []socketmode.Event{
+		{Type: socketmode.EventTypeConnected},
+		{Type: socketmode.EventTypeEventsAPI, Request: &socketmode.Request{Type: "events_api", EnvelopeID: "E0001", Payload: json.RawMessage(event)}},
+		{Type: socketmode.EventTypeSlashCommand, Data: cmd, Request: &socketmode.Request{Type: "slash_commands", EnvelopeID: "E0002"}},
+		{Type: socketmode.EventTypeInteractive, Data: callback, Request: &socketmode.Request{Type: "interactive", EnvelopeID: "E0003", Payload: json.RawMessage(interaction)}},
+	}
+	for _, evt := range evts {
+		handleSocketEvent(ctx, client, env, evt)
+	}
+
+	// Each request is enqueued in the same way as over HTTP
+	var kinds []string
+	var jobs []*commandJob
+	for _, p := range aws.payloads[env.FunctionName] {
+		envelope := &jobEnvelope{}
+		if err := json.Unmarshal(p, envelope); err != nil {
+			t.Fatal(err)
+		}
+		kinds = append(kinds, envelope.Job.Kind)
+		jobs = append(jobs, envelope.Job)
+	}
+	if want := []string{eventJob, slashCommandJob, interactiveJob}; strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("enqueued %v, want %v", kinds, want)
+	}
+	if jobs[0].EventID != "Ev0001" || jobs[0].Body != event {
+		t.Errorf("event job = %+v", jobs[0])
+	}
+	if jobs[2].Body != interaction {
+		t.Errorf("interactive job = %+v", jobs[2])
+	}
+
+	// The slash command is encoded in the same format as sent over HTTP
+	form, err := url.ParseQuery(jobs[1].Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{"team_id": "T0001", "channel_id": "C0123", "user_id": "U0001", "command": "/hitter", "text": "hit 2 --ex <@U0003>", "response_url": cmd.ResponseURL} {
+		if got := form.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestHandleSocketEventEnqueueFailure(t *testing.T) {
+	const event = `{"type":"event_callback","event_id":"Ev0002","event":{"type":"app_mention","text":"<@UHITTERBOT> hit","user":"U0001","channel":"C0123","ts":"1595673533.002100","event_ts":"1595673533.002100"}}`
+
+	env, aws := setupRetryTest(t)
+	env.QueueMode = "unknown"
+
+	var buf bytes.Buffer
+	logging.SetOutput(&buf)
+	t.Cleanup(func() { logging.SetOutput(os.Stderr) })
+
+	client := socketmode.New(slack.New("xoxb-test", slack.OptionAppLevelToken("xapp-test")))
+	evt := socketmode.Event{Type: socketmode.EventTypeEventsAPI, Request: &socketmode.Request{Type: "events_api", EnvelopeID: "E0001", Payload: json.RawMessage(event)}}
+	handleSocketEvent(context.Background(), client, env, evt)
+
+	if len(aws.payloads[env.FunctionName]) != 0 {
+		t.Error("the event is enqueued")
+	}
+
+	// Slack has already been acknowledged, so the failure is only in the log
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]string{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("not a JSON line: %q", line)
+		}
+		if entry["level"] == "error" && strings.Contains(entry["msg"], "Failed to handle the socket mode request") {
+			found = entry["envelope_id"] == "E0001" && strings.Contains(entry["msg"], "500")
+		}
+	}
+	if !found {
+		t.Errorf("the failure is not logged at the error level:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// The commands keep their state per channel as JSON in the table of STATE_TABLE_NAME,
+// under keys such as "hit#C0123".
+
+// The state of a channel that is not used for this many days is deleted
+const stateTTLDays = 180
+
+// Number of attempts to update the state when another request updates it at the same time
+const stateUpdateAttempts = 3
+
+var errStateNotConfigured = errors.New("The state table is not configured, please set STATE_TABLE_NAME")
+
+func stateKey(command string, channel string, names ...string) string {
+	key := command + "#" + channel
+	for _, n := range names {
+		key += "#" + n
+	}
+
+	return key
+}
+
+func stateEnabled() bool {
+	return envconf != nil && envconf.StateTableName != ""
+}
+
+// Read the state into v, a pointer which is set to the zero value if there is no state yet
+func loadState(ctx context.Context, store stateStore, key string, v interface{}) (*storage.StateItem, error) {
+	if !stateEnabled() {
+		return nil, errStateNotConfigured
+	}
+
+	// Nothing is left from the previous attempt of updateState
+	rv := reflect.ValueOf(v).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+
+	item, err := store.getStateItem(ctx, envconf.StateTableName, key)
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to get the state: ", key, err)
+		return nil, err
+	}
+	if item.Data != "" {
+		if err := json.Unmarshal([]byte(item.Data), v); err != nil {
+			logger(ctx).Println("[ERROR] Failed to decode the state: ", key, err)
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+// Read the state into v, change it with update and write it back.
+// When another request has updated the state in the meantime, v is read again and update is called again.
+func updateState(ctx context.Context, store stateStore, key string, v interface{}, update func() error) error {
+	var err error
+	for i := 0; i < stateUpdateAttempts; i++ {
+		var item *storage.StateItem
+		item, err = loadState(ctx, store, key, v)
+		if err != nil {
+			return err
+		}
+		if err = update(); err != nil {
+			return err
+		}
+
+		var b []byte
+		if b, err = json.Marshal(v); err != nil {
+			return err
+		}
+		item.Data = string(b)
+
+		err = store.putStateItem(ctx, envconf.StateTableName, item, stateTTLDays)
+		if err != storage.ErrStateConflict {
+			break
+		}
+
+		// Output debug log
+		logger(ctx).Debugf("state conflict: %s %d\n", key, item.Version)
+	}
+	if err != nil {
+		logger(ctx).Println("[ERROR] Failed to update the state: ", key, err)
+	}
+
+	return err
+}
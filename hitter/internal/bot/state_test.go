@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uchimanajet7/hitter/hitter/internal/storage"
+)
+
+// Another request updates the state right after it is read
+type racingStateStore struct {
+	*memoryStateStore
+	races int
+}
+
+func (s *racingStateStore) getStateItem(ctx context.Context, tableName string, id string) (*storage.StateItem, error) {
+	item, err := s.memoryStateStore.getStateItem(ctx, tableName, id)
+	if err == nil && s.races > 0 {
+		s.races--
+		other := *item
+		other.Data = `{"rounds":[{"time":1,"users":["U0009"]}]}`
+		s.memoryStateStore.putStateItem(ctx, tableName, &other, stateTTLDays)
+	}
+
+	return item, err
+}
+
+func TestUpdateStateConflict(t *testing.T) {
+	envconf = &envConfig{StateTableName: "HitterStateTable"}
+	store := &racingStateStore{memoryStateStore: newMemoryStateStore(), races: 1}
+
+	history := &hitHistory{}
+	err := updateState(context.Background(), store, "hit#C0123", history, func() error {
+		history.Rounds = append(history.Rounds, &hitRound{Time: 2, Users: []string{"U0001"}})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The update is applied on top of the state written by the other request
+	if _, err := loadState(context.Background(), store, "hit#C0123", history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Rounds) != 2 || history.Rounds[0].Users[0] != "U0009" || history.Rounds[1].Users[0] != "U0001" {
+		t.Errorf("got %+v", history.Rounds)
+	}
+
+	// The update fails when the state keeps changing
+	store.races = stateUpdateAttempts
+	err = updateState(context.Background(), store, "hit#C0123", history, func() error { return nil })
+	if err != storage.ErrStateConflict {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Split the command text like a shell does.
+// - Any unicode space separates tokens, including tabs, newlines and non-breaking spaces
+// - Double or single quotes group words, and smart quotes pasted from documents are also accepted
+// - A backslash escapes the next character
+// - Slack markup enclosed in "<>" is kept as one token
+// - "--opt=value" is split into the option and its value when binding
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	userToken
+	usergroupToken
+	channelToken
+	linkToken
+)
+
+type token struct {
+	kind tokenKind
+	// The ID for mentions, user groups and channels, and the URL for links
+	value string
+	// Byte offsets of the token in the input text
+	start int
+	end   int
+	// Quoted tokens are never treated as options
+	quoted bool
+}
+
+// Give a type to a part of the token, such as the value of "--ex=<@W017HPXHDF0>"
+func (t *token) typed(str string) *token {
+	if str == t.value {
+		return t
+	}
+	v := &token{start: t.start, end: t.end}
+	v.kind, v.value = parseSlackMarkup(str)
+
+	return v
+}
+
+type tokenError struct {
+	message string
+	token   string
+	// Byte offset of the token in the input text
+	pos   int
+	input string
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("%s: %s (column %d)", e.message, e.token, e.column())
+}
+
+func (e *tokenError) column() int {
+	return utf8.RuneCountInString(e.input[:e.pos]) + 1
+}
+
+// Show the input with a marker under the offending token
+func (e *tokenError) pointer() string {
+	width := utf8.RuneCountInString(e.token)
+	if width < 1 {
+		width = 1
+	}
+	line := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, e.input)
+
+	return line + "\n" + strings.Repeat(" ", e.column()-1) + strings.Repeat("^", width)
+}
+
+func newTokenError(t *token, input string, message string) *tokenError {
+	return &tokenError{message: message, token: input[t.start:t.end], pos: t.start, input: input}
+}
+
+// Closing quote for each opening quote
+var quotePairs = map[rune]rune{
+	'"':      '"',
+	'\'':     '\'',
+	'\u201c': '\u201d', // “ ”
+	'\u2018': '\u2019', // ‘ ’
+	'\u201e': '\u201d', // „ ”
+}
+
+func isSpace(r rune) bool {
+	// Zero width spaces are not included in unicode.IsSpace
+	return unicode.IsSpace(r) || r == '\u200b' || r == '\ufeff'
+}
+
+func tokenize(text string) ([]*token, error) {
+	var tokens []*token
+	z := &tokenizer{text: text}
+	for {
+		t, err := z.next()
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return tokens, nil
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// Reads the tokens one by one, so that the rest of the text can be taken as is
+type tokenizer struct {
+	text string
+	// Byte offset of the next character to read
+	pos int
+}
+
+// Skip the spaces and return the offset of the next token, or the length of the text at the end
+func (z *tokenizer) skipSpaces() int {
+	for z.pos < len(z.text) {
+		r, size := utf8.DecodeRuneInString(z.text[z.pos:])
+		if !isSpace(r) {
+			break
+		}
+		z.pos += size
+	}
+
+	return z.pos
+}
+
+// The next token, or nil at the end of the text
+func (z *tokenizer) next() (*token, error) {
+	text := z.text
+	i := z.skipSpaces()
+	if i >= len(text) {
+		return nil, nil
+	}
+
+	cur := &token{start: i}
+	var b strings.Builder
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if isSpace(r) {
+			break
+		}
+
+		// A backslash escapes the next character
+		if r == '\\' {
+			if i+size >= len(text) {
+				cur.end = len(text)
+				return nil, newTokenError(cur, text, "The escape character is not followed by anything")
+			}
+			n, nsize := utf8.DecodeRuneInString(text[i+size:])
+			b.WriteRune(n)
+			i += size + nsize
+			continue
+		}
+
+		// Mentions, channels and links such as "<!subteam^S0123|@team name>" are kept as one token
+		if r == '<' && (b.Len() == 0 || strings.HasSuffix(b.String(), "=")) {
+			if j := strings.IndexByte(text[i:], '>'); j > 0 {
+				b.WriteString(text[i : i+j+1])
+				i += j + 1
+				continue
+			}
+		}
+
+		// Quotes are only recognized at the beginning of a token or right after "="
+		closing, ok := quotePairs[r]
+		if ok && (b.Len() == 0 || strings.HasSuffix(b.String(), "=")) {
+			cur.quoted = cur.quoted || b.Len() == 0
+			j := i + size
+			closed := false
+			for j < len(text) {
+				q, qsize := utf8.DecodeRuneInString(text[j:])
+				if q == closing {
+					j += qsize
+					closed = true
+					break
+				}
+				// Inside double quotes, a backslash escapes the next character
+				if q == '\\' && r != '\'' && j+qsize < len(text) {
+					n, nsize := utf8.DecodeRuneInString(text[j+qsize:])
+					b.WriteRune(n)
+					j += qsize + nsize
+					continue
+				}
+				b.WriteRune(q)
+				j += qsize
+			}
+			if !closed {
+				t := &token{start: i, end: len(text)}
+				return nil, newTokenError(t, text, "The quote is not closed")
+			}
+			i = j
+			continue
+		}
+
+		b.WriteRune(r)
+		i += size
+	}
+
+	cur.kind, cur.value = parseSlackMarkup(b.String())
+	if cur.quoted {
+		cur.kind, cur.value = textToken, b.String()
+	}
+	cur.value = unescapeSlackText(cur.value)
+	cur.end = i
+	z.pos = i
+
+	return cur, nil
+}
+
+// Split the text into the first field and the rest of the text
+func splitHead(text string) (string, string) {
+	text = strings.TrimLeftFunc(text, isSpace)
+	i := strings.IndexFunc(text, isSpace)
+	if i < 0 {
+		return text, ""
+	}
+
+	return text[:i], text[i:]
+}
+
+// https://api.slack.com/reference/surfaces/formatting#retrieving-messages
+// <@W017HPXHDF0>, <!subteam^S0123|@team>, <#C0123|general>, <https://example.com|example>
+func parseSlackMarkup(str string) (tokenKind, string) {
+	if !strings.HasPrefix(str, "<") || !strings.HasSuffix(str, ">") {
+		return textToken, str
+	}
+
+	// The label after "|" is only for display
+	inner := str[1 : len(str)-1]
+	label := ""
+	if i := strings.Index(inner, "|"); i >= 0 {
+		inner, label = inner[:i], inner[i+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(inner, "@"):
+		return userToken, inner[1:]
+	case strings.HasPrefix(inner, "#"):
+		return channelToken, inner[1:]
+	case strings.HasPrefix(inner, "!subteam^"):
+		return usergroupToken, strings.TrimPrefix(inner, "!subteam^")
+	case strings.HasPrefix(inner, "!"):
+		// Special mentions such as <!here>
+		if label != "" {
+			return textToken, label
+		}
+		return textToken, "@" + inner[1:]
+	default:
+		return linkToken, inner
+	}
+}
+
+// https://api.slack.com/reference/surfaces/formatting#escaping
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func unescapeSlackText(text string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(text)
+}
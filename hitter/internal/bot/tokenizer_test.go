@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The kind and value of each token, such as "user:U0001" or "text:abc", and "quoted:" for quoted text
+func tokenStrings(tokens []*token) []string {
+	kinds := map[tokenKind]string{
+		textToken:      "text",
+		userToken:      "user",
+		usergroupToken: "usergroup",
+		channelToken:   "channel",
+		linkToken:      "link",
+	}
+
+	result := []string{}
+	for _, t := range tokens {
+		kind := kinds[t.kind]
+		if t.quoted {
+			kind = "quoted"
+		}
+		result = append(result, kind+":"+t.value)
+	}
+
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"spaces", "  a  b ", []string{"text:a", "text:b"}},
+		{"tabs and newlines", "a\tb\nc\r\nd", []string{"text:a", "text:b", "text:c", "text:d"}},
+		{"non-breaking and zero width spaces", "a\u00a0b\u200bc\u3000d", []string{"text:a", "text:b", "text:c", "text:d"}},
+		{"double quotes", `"code review" x`, []string{"quoted:code review", "text:x"}},
+		{"single quotes", `'code review'`, []string{"quoted:code review"}},
+		{"empty quotes", `"" x`, []string{"quoted:", "text:x"}},
+		{"smart double quotes", "“code review”", []string{"quoted:code review"}},
+		{"smart single quotes", "‘code review’", []string{"quoted:code review"}},
+		{"low smart quotes", "„code review”", []string{"quoted:code review"}},
+		{"other quote inside quotes", `"it's" '"a"'`, []string{"quoted:it's", `quoted:"a"`}},
+		{"quote inside a word", `it's`, []string{"text:it's"}},
+		{"escaped space", `a\ b c`, []string{"text:a b", "text:c"}},
+		{"escaped quote", `\"a`, []string{`text:"a`}},
+		{"escape inside double quotes", `"a \"b\" \\"`, []string{`quoted:a "b" \`}},
+		{"no escape inside single quotes", `'a\b'`, []string{`quoted:a\b`}},
+		{"option with value", `--ttl=7`, []string{"text:--ttl=7"}},
+		{"option with quoted value", `--items="code review" x`, []string{"text:--items=code review", "text:x"}},
+		{"option with mention", `--ex=<@W017HPXHDF0>`, []string{"text:--ex=<@W017HPXHDF0>"}},
+		{"end of options", `-- --fair`, []string{"text:--", "text:--fair"}},
+		{"user", `<@U0001>`, []string{"user:U0001"}},
+		{"user with label", `<@U0001|alice>`, []string{"user:U0001"}},
+		{"enterprise user", `<@W017HPXHDF0>`, []string{"user:W017HPXHDF0"}},
+		{"usergroup with spaces in the label", `<!subteam^S0123|@team name> x`, []string{"usergroup:S0123", "text:x"}},
+		{"channel", `<#C0123|general>`, []string{"channel:C0123"}},
+		{"link", `<https://example.com/a?b=c&amp;d=e|example>`, []string{"link:https://example.com/a?b=c&d=e"}},
+		{"special mention", `<!here>`, []string{"text:@here"}},
+		{"quoted mention", `"<@U0001>"`, []string{"quoted:<@U0001>"}},
+		{"markup inside a word", `a<@U0001>`, []string{"text:a<@U0001>"}},
+		{"unclosed markup", `<@U0001`, []string{"text:<@U0001"}},
+		{"escaped slack text", `a&amp;b &lt;c&gt;`, []string{"text:a&b", "text:<c>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tokenStrings(tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	in := "hit \"a b\" <@U0001>"
+	tokens, err := tokenize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"hit", `"a b"`, "<@U0001>"}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, tk := range tokens {
+		if got := in[tk.start:tk.end]; got != want[i] {
+			t.Errorf("token %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantError   string
+		wantPointer string
+	}{
+		{
+			name:        "unterminated double quote",
+			in:          `a "b c`,
+			wantError:   `The quote is not closed: "b c (column 3)`,
+			wantPointer: "a \"b c\n  ^^^^",
+		},
+		{
+			name:        "unterminated single quote",
+			in:          `'b`,
+			wantError:   `The quote is not closed: 'b (column 1)`,
+			wantPointer: "'b\n^^",
+		},
+		{
+			name:        "unterminated smart quote after multibyte text",
+			in:          "あい “う",
+			wantError:   "The quote is not closed: “う (column 4)",
+			wantPointer: "あい “う\n   ^^",
+		},
+		{
+			name:        "closing quote of another kind",
+			in:          "“a\"",
+			wantError:   "The quote is not closed: “a\" (column 1)",
+			wantPointer: "“a\"\n^^^",
+		},
+		{
+			name:        "trailing escape",
+			in:          "a\nbc\\",
+			wantError:   `The escape character is not followed by anything: bc\ (column 3)`,
+			wantPointer: "a bc\\\n  ^^^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokenize(tt.in)
+			te, ok := err.(*tokenError)
+			if !ok {
+				t.Fatalf("tokenize(%q) error = %v, want a tokenError", tt.in, err)
+			}
+			if te.Error() != tt.wantError {
+				t.Errorf("Error() = %q, want %q", te.Error(), tt.wantError)
+			}
+			if te.pointer() != tt.wantPointer {
+				t.Errorf("pointer() =\n%s\nwant\n%s", te.pointer(), tt.wantPointer)
+			}
+		})
+	}
+}
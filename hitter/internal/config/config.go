@@ -0,0 +1,88 @@
+// Package config loads the settings shared by the bot and the redirect from environment variables.
+package config
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// Common is embedded in the configuration of each function.
+type Common struct {
+	URLTableName string `envconfig:"URL_TABLE_NAME" required:"true"`
+	// "debug", "info", "warn" or "error", DEBUG_LOG=true is the same as "debug"
+	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+	DebugLog bool   `envconfig:"DEBUG_LOG"`
+	// Only used for the HTTP server mode
+	ListenAddr string `envconfig:"LISTEN_ADDR" default:":8080"`
+	// Only used to connect to DynamoDB Local, such as "http://localhost:8000"
+	DynamoDBEndpoint string `envconfig:"DYNAMODB_ENDPOINT"`
+}
+
+func (c *Common) common() *Common {
+	return c
+}
+
+// Spec is a configuration struct embedding Common.
+type Spec interface {
+	common() *Common
+}
+
+// Load fills the spec from environment variables and applies the log settings.
+func Load(spec Spec) error {
+	err := envconfig.Process("", spec)
+	if err != nil {
+		log.Println("[ERROR] Failed to load environment variables: ", err)
+		return err
+	}
+
+	// Log output settings
+	level, err := logging.ParseLevel(spec.common().LogLevel)
+	if err != nil {
+		log.Println("[ERROR] Failed to load environment variables: ", err)
+		return err
+	}
+	if spec.common().DebugLog {
+		level = logging.LevelDebug
+	}
+	logging.SetLevel(level)
+	logging.Install()
+
+	// Output debug log
+	logging.Default().Debugf("env: %+v\n", maskSecrets(spec))
+
+	return nil
+}
+
+// The values of the variables named like a secret are not written to the log
+func maskSecrets(spec Spec) map[string]string {
+	masked := map[string]string{}
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.Anonymous {
+				walk(v.Field(i))
+				continue
+			}
+			name := f.Tag.Get("envconfig")
+			if name == "" {
+				continue
+			}
+			value := fmt.Sprint(v.Field(i).Interface())
+			secret := strings.Contains(name, "TOKEN") || strings.Contains(name, "SECRET")
+			if value != "" && secret && !strings.HasSuffix(name, "_EXPIRY") {
+				value = "[REDACTED]"
+			}
+			masked[name] = value
+		}
+	}
+	walk(reflect.Indirect(reflect.ValueOf(spec)))
+
+	return masked
+}
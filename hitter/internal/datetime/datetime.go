@@ -0,0 +1,153 @@
+// Package datetime formats the dates shown in slack, file names and HTTP headers.
+package datetime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+// HTTPDateString converts the date into the format of HTTP headers in GMT.
+func HTTPDateString(dateStr string) (string, error) {
+	// Must be displayed in GMT time zone
+	t, err := ParseToGMT(dateStr)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatHTTP(t), nil
+}
+
+// FileNameDateString converts the date into a string used in file names in JST.
+func FileNameDateString(dateStr string) (string, error) {
+	t, err := ParseToJST(dateStr)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatFileName(t), nil
+}
+
+// DisplayDateString converts the date into the format shown in slack in JST.
+// The date is moved forward by limitSeconds if it is a number.
+func DisplayDateString(dateStr string, limitSeconds string) (string, error) {
+	t, err := ParseToJST(dateStr)
+	if err != nil {
+		return "", err
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitSeconds))
+	if err == nil {
+		t = t.Add(time.Duration(limit) * time.Second)
+	}
+
+	return FormatDisplay(t), nil
+}
+
+// StartTimes returns the start times of n slots of the length, as shown in slack in JST.
+// The first slot starts at the clock time, such as "09:30", on the day of the date, or at the date itself if the clock is empty.
+func StartTimes(dateStr string, clock string, slot time.Duration, n int) ([]string, error) {
+	t, err := ParseToJST(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if clock != "" {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, err
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), c.Hour(), c.Minute(), 0, 0, t.Location())
+	}
+
+	// Seconds are only shown when the slots do not start on the minute
+	layout := "15:04"
+	if slot%time.Minute != 0 {
+		layout = "15:04:05"
+	}
+
+	result := []string{}
+	for i := 0; i < n; i++ {
+		result = append(result, t.Add(slot*time.Duration(i)).Format(layout))
+	}
+
+	return result, nil
+}
+
+// ParseToJST parses the date in any format and returns it in JST.
+func ParseToJST(dateStr string) (time.Time, error) {
+	var result time.Time
+
+	// Set the locale to JST.
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return result, err
+	}
+
+	// Parsing in JST locales
+	t, err := dateparse.ParseAny(dateStr)
+	if err != nil {
+		return result, err
+	}
+	result = t.In(jst)
+
+	return result, err
+}
+
+// ParseInJST parses the date in any format and returns it in JST.
+// Unlike ParseToJST, a date without a time zone is read as JST instead of UTC.
+func ParseInJST(dateStr string) (time.Time, error) {
+	var result time.Time
+
+	// Set the locale to JST.
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return result, err
+	}
+
+	t, err := dateparse.ParseIn(dateStr, jst)
+	if err != nil {
+		return result, err
+	}
+	result = t.In(jst)
+
+	return result, err
+}
+
+// ParseToGMT parses the date in any format and returns it in GMT.
+func ParseToGMT(dateStr string) (time.Time, error) {
+	var result time.Time
+
+	// Set the locale to GMT.
+	gmt, err := time.LoadLocation("GMT")
+	if err != nil {
+		return result, err
+	}
+
+	// Parsing in GMT locales
+	t, err := dateparse.ParseAny(dateStr)
+	if err != nil {
+		return result, err
+	}
+	result = t.In(gmt)
+
+	return result, err
+}
+
+// FormatDisplay formats the time as shown in slack.
+func FormatDisplay(dateTime time.Time) string {
+	return dateTime.Format("2006/01/02 Mon 15:04:05 MST")
+}
+
+// FormatFileName formats the time for file names.
+func FormatFileName(dateTime time.Time) string {
+	return dateTime.Format("20060102_Mon_150405_MST")
+}
+
+// FormatHTTP formats the time for HTTP headers.
+func FormatHTTP(dateTime time.Time) string {
+	// Date: Sat, 23 Dec 2019 06:53:29 GMT
+	return dateTime.Format("Mon, 02 Jan 2006 15:04:05 MST")
+}
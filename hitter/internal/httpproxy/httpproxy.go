@@ -0,0 +1,110 @@
+// Package httpproxy runs the handlers written for API Gateway as a standalone HTTP server,
+// so the functions can run on-prem and the whole flow can be tested locally.
+package httpproxy
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Slack and the redirect send small requests, so anything larger is rejected
+const maxRequestBodySize = 1 << 20
+
+// Time to wait for the running requests when shutting down
+const shutdownTimeout = 30 * time.Second
+
+// Handler is the same function as the one passed to lambda.Start for API Gateway.
+type Handler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// ListenAndServe serves the handler on addr until SIGINT or SIGTERM is received,
+// and returns after the running requests have finished.
+func ListenAndServe(addr string, handler Handler) error {
+	server := &http.Server{Addr: addr, Handler: NewHandler(handler)}
+
+	// Stop on SIGINT or SIGTERM
+	idle := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		s := <-sig
+		log.Println("[HTTP] Shutting down: ", s)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := server.Shutdown(ctx)
+		if err != nil {
+			log.Println("[ERROR] Failed to shut down the HTTP server: ", err)
+		}
+		close(idle)
+	}()
+
+	log.Println("[HTTP] Listening on ", addr)
+	err := server.ListenAndServe()
+	if err != http.ErrServerClosed {
+		return err
+	}
+	<-idle
+
+	return nil
+}
+
+// NewHandler translates the HTTP request into the same event as API Gateway sends to AWS Lambda.
+func NewHandler(handler Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		request := events.APIGatewayProxyRequest{}
+		request.Path = r.URL.Path
+		request.HTTPMethod = r.Method
+		request.Body = string(body)
+		request.Headers = make(map[string]string)
+		request.MultiValueHeaders = make(map[string][]string)
+		for k, v := range r.Header {
+			request.Headers[k] = strings.Join(v, ",")
+			request.MultiValueHeaders[k] = v
+		}
+		request.QueryStringParameters = make(map[string]string)
+		request.MultiValueQueryStringParameters = make(map[string][]string)
+		for k, v := range r.URL.Query() {
+			request.QueryStringParameters[k] = v[0]
+			request.MultiValueQueryStringParameters[k] = v
+		}
+		// Same as the "{proxy+}" resource of API Gateway
+		request.PathParameters = map[string]string{"proxy": strings.TrimPrefix(r.URL.Path, "/")}
+		request.RequestContext.HTTPMethod = r.Method
+		request.RequestContext.Path = r.URL.Path
+		request.RequestContext.Identity.SourceIP = r.RemoteAddr
+
+		res, err := handler(r.Context(), request)
+		if err != nil {
+			// Same as API Gateway when the function returns an error
+			log.Println("[ERROR] The handler returned an error: ", err)
+			http.Error(w, `{"message": "Internal server error"}`, http.StatusBadGateway)
+			return
+		}
+
+		for k, v := range res.Headers {
+			w.Header().Set(k, v)
+		}
+		for k, vs := range res.MultiValueHeaders {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+		w.WriteHeader(res.StatusCode)
+		w.Write([]byte(res.Body))
+	})
+}
@@ -0,0 +1,104 @@
+package httpproxy
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	var got events.APIGatewayProxyRequest
+	server := httptest.NewServer(NewHandler(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		got = request
+		res := events.APIGatewayProxyResponse{StatusCode: 302, Body: `{"result": "ok"}`}
+		res.Headers = map[string]string{"Location": "https://example.com/"}
+		res.MultiValueHeaders = map[string][]string{"X-Multi": {"a", "b"}}
+		return res, nil
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/v1/abc?q=1&q=2", strings.NewReader("token=xxx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("X-Slack-Signature", "v0=123")
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Add("Accept", "application/json")
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	// The request is passed to the handler in the same way as API Gateway
+	if got.HTTPMethod != "POST" || got.Path != "/v1/abc" || got.Body != "token=xxx" {
+		t.Errorf("request = %s %s %q", got.HTTPMethod, got.Path, got.Body)
+	}
+	if got.PathParameters["proxy"] != "v1/abc" {
+		t.Errorf("proxy = %q", got.PathParameters["proxy"])
+	}
+	if got.Headers["X-Slack-Signature"] != "v0=123" || got.Headers["Accept"] != "text/plain,application/json" {
+		t.Errorf("headers = %v", got.Headers)
+	}
+	if len(got.MultiValueHeaders["Accept"]) != 2 {
+		t.Errorf("multi value headers = %v", got.MultiValueHeaders)
+	}
+	if got.QueryStringParameters["q"] != "1" || len(got.MultiValueQueryStringParameters["q"]) != 2 {
+		t.Errorf("query = %v %v", got.QueryStringParameters, got.MultiValueQueryStringParameters)
+	}
+
+	// The response of the handler is written as it is
+	if res.StatusCode != 302 || string(body) != `{"result": "ok"}` {
+		t.Errorf("response = %d %s", res.StatusCode, body)
+	}
+	if res.Header.Get("Location") != "https://example.com/" || len(res.Header["X-Multi"]) != 2 {
+		t.Errorf("response headers = %v", res.Header)
+	}
+}
+
+func TestHandlerTooLarge(t *testing.T) {
+	called := false
+	server := httptest.NewServer(NewHandler(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		called = true
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}))
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/", "text/plain", strings.NewReader(strings.Repeat("a", maxRequestBodySize+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("got %d, called %v, want 413 without calling the handler", res.StatusCode, called)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	server := httptest.NewServer(NewHandler(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{}, errors.New("failed")
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	// Same as API Gateway when the function returns an error
+	if res.StatusCode != http.StatusBadGateway || !strings.Contains(string(body), "Internal server error") {
+		t.Errorf("got %d %s, want 502", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,22 @@
+// Package lazy creates a value on first use and keeps it while the process is alive,
+// such as the environment and the clients while the Lambda container is warm.
+package lazy
+
+import "sync"
+
+// Value is created once by the first call of Get, even if the creation fails.
+// The zero value is ready to use.
+type Value struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// Get returns the value and the error of the first creation.
+func (v *Value) Get(create func() (interface{}, error)) (interface{}, error) {
+	v.once.Do(func() {
+		v.value, v.err = create()
+	})
+
+	return v.value, v.err
+}
@@ -0,0 +1,40 @@
+package lazy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	var v Value
+	calls := 0
+	create := func() (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	first, err := v.Get(create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := v.Get(create)
+	if first != 1 || second != 1 || calls != 1 {
+		t.Errorf("got %v and %v after %d calls, want 1 and 1 after 1 call", first, second, calls)
+	}
+}
+
+func TestValueError(t *testing.T) {
+	var v Value
+	want := errors.New("failed")
+	calls := 0
+	create := func() (interface{}, error) {
+		calls++
+		return nil, want
+	}
+
+	// The error is kept in the same way as the value
+	v.Get(create)
+	if _, err := v.Get(create); err != want || calls != 1 {
+		t.Errorf("got %v after %d calls, want %v after 1 call", err, calls, want)
+	}
+}
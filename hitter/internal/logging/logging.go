@@ -0,0 +1,213 @@
+// Package logging provides the structured log shared by the bot and the redirect.
+//
+// Each line is a JSON object with the level, the message and the fields of the request,
+// such as the slack event ID and the Lambda request ID, so that it can be queried with CloudWatch Logs Insights.
+// The level is taken from the tag at the start of the message, such as "[ERROR]",
+// so the messages are written in the same way as with the standard log package.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of the log.
+type Level int32
+
+// Levels of the log, from the most verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel returns the level of the name, such as "info".
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return l, nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level: %s", name)
+}
+
+// Tags of the messages and their levels, the other tags are info
+var tagLevels = map[string]Level{
+	"DEBUG":    LevelDebug,
+	"WARN":     LevelWarn,
+	"REJECTED": LevelWarn,
+	"ERROR":    LevelError,
+}
+
+var minLevel = int32(LevelInfo)
+
+// SetLevel outputs the log of the level and above.
+func SetLevel(l Level) {
+	atomic.StoreInt32(&minLevel, int32(l))
+}
+
+// Enabled reports whether the log of the level is output.
+func Enabled(l Level) bool {
+	return int32(l) >= atomic.LoadInt32(&minLevel)
+}
+
+// DebugEnabled reports whether the debug log is enabled.
+func DebugEnabled() bool {
+	return Enabled(LevelDebug)
+}
+
+// Fields are added to every line of the log, such as "event_id".
+type Fields map[string]string
+
+var (
+	outputMu sync.Mutex
+	output   io.Writer = os.Stderr
+)
+
+// SetOutput changes the destination of the log.
+func SetOutput(w io.Writer) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	output = w
+}
+
+// Logger writes the log with its fields.
+type Logger struct {
+	fields Fields
+}
+
+var std = &Logger{}
+
+// Default returns the logger without fields.
+func Default() *Logger {
+	return std
+}
+
+// With returns a logger with the fields added.
+func (l *Logger) With(fields Fields) *Logger {
+	merged := Fields{}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+
+	return &Logger{fields: merged}
+}
+
+// Printf writes the message, with the level taken from its tag.
+func (l *Logger) Printf(format string, args ...interface{}) {
+	l.write(LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Println writes the operands separated by spaces, with the level taken from the tag.
+func (l *Logger) Println(args ...interface{}) {
+	l.write(LevelInfo, fmt.Sprintln(args...))
+}
+
+// Debugf writes the message only when the debug level is enabled.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !DebugEnabled() {
+		return
+	}
+	l.write(LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) write(level Level, msg string) {
+	msg = strings.TrimSpace(msg)
+	tag := ""
+	if strings.HasPrefix(msg, "[") {
+		if i := strings.Index(msg, "]"); i > 0 {
+			tag, msg = msg[1:i], strings.TrimSpace(msg[i+1:])
+			if tl, ok := tagLevels[tag]; ok {
+				level = tl
+			}
+		}
+	}
+	if !Enabled(level) {
+		return
+	}
+
+	entry := map[string]string{}
+	for k, v := range l.fields {
+		entry[k] = Redact(v)
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = Redact(msg)
+	if tag != "" && tag != "DEBUG" {
+		entry["tag"] = tag
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	output.Write(append(b, '\n'))
+}
+
+type contextKey struct{}
+
+// NewContext returns a context whose logger has the fields added.
+func NewContext(ctx context.Context, fields Fields) context.Context {
+	return context.WithValue(ctx, contextKey{}, FromContext(ctx).With(fields))
+}
+
+// FromContext returns the logger of the context, or the logger without fields.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+			return l
+		}
+	}
+
+	return std
+}
+
+// The standard log package writes each call at once, so a line is a message
+type stdWriter struct{}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	std.write(LevelInfo, string(p))
+
+	return len(p), nil
+}
+
+var installOnce sync.Once
+
+// Install sends the output of the standard log package to the structured log.
+func Install() {
+	installOnce.Do(func() {
+		log.SetFlags(0)
+		log.SetPrefix("")
+		log.SetOutput(stdWriter{})
+	})
+}
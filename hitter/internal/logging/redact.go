@@ -0,0 +1,27 @@
+package logging
+
+import "regexp"
+
+// Secrets are replaced before they are written, even in the debug log of whole requests
+var redactions = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	// Slack tokens, such as "xoxb-..." and the app-level token "xapp-..."
+	{regexp.MustCompile(`\b(xox[a-z]|xapp)-[0-9A-Za-z-]+`), "${1}-[REDACTED]"},
+	{regexp.MustCompile(`(Bearer )[^\s"]+`), "${1}[REDACTED]"},
+	// The signature of the pre-signed URLs
+	{regexp.MustCompile(`(X-Amz-(?:Signature|Credential|Security-Token)=)[^&\s"]+`), "${1}[REDACTED]"},
+	// The verification token sent with the events and the slash commands
+	{regexp.MustCompile(`\b(token=)[^&\s"]+`), "${1}[REDACTED]"},
+	{regexp.MustCompile(`("token"\s*:\s*")[^"]*`), "${1}[REDACTED]"},
+}
+
+// Redact replaces the tokens and the signatures in the text.
+func Redact(text string) string {
+	for _, r := range redactions {
+		text = r.pattern.ReplaceAllString(text, r.replace)
+	}
+
+	return text
+}
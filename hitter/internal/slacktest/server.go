@@ -0,0 +1,364 @@
+// Package slacktest provides a fake slack Web API server for tests.
+// It implements the methods used by hitter and records every call,
+// so tests can assert exactly which messages were posted.
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+)
+
+// Names of the calls that are not Web API methods
+const (
+	FileDownload = "files.download"
+	ResponseURL  = "response_url"
+)
+
+// BotUserID is returned from auth.test.
+const BotUserID = "UHITTERBOT"
+
+// Call is a request received by the server.
+type Call struct {
+	// The Web API method such as "chat.postMessage", FileDownload or ResponseURL
+	Method string
+	// Form values of the request, including the fields of multipart requests
+	Values url.Values
+	// The JSON sent to a response URL, or the uploaded file
+	Body []byte
+	// The last element of the path for file downloads and response URLs
+	Name string
+}
+
+// Message decodes the message posted with chat.postMessage, chat.postEphemeral or a response URL.
+func (c *Call) Message() (*slack.Msg, error) {
+	msg := &slack.Msg{}
+	if c.Method == ResponseURL {
+		err := json.Unmarshal(c.Body, msg)
+		return msg, err
+	}
+
+	msg.Channel = c.Values.Get("channel")
+	msg.User = c.Values.Get("user")
+	msg.Text = c.Values.Get("text")
+	msg.ThreadTimestamp = c.Values.Get("thread_ts")
+	if blocks := c.Values.Get("blocks"); blocks != "" {
+		err := json.Unmarshal([]byte(blocks), &msg.Blocks)
+		if err != nil {
+			return msg, err
+		}
+	}
+
+	return msg, nil
+}
+
+// Server is a fake slack Web API server.
+type Server struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	calls   []*Call
+	members map[string][]string
+	users   map[string]slack.User
+	files   map[string][]byte
+	errors  map[string]string
+	// Errors returned only for the calls with a parameter, such as the channel
+	callErrors []*callError
+	ts         int
+}
+
+type callError struct {
+	method     string
+	param      string
+	value      string
+	slackError string
+}
+
+// NewServer starts a server. It must be closed with Close.
+func NewServer() *Server {
+	s := &Server{}
+	s.members = make(map[string][]string)
+	s.users = make(map[string]slack.User)
+	s.files = make(map[string][]byte)
+	s.errors = make(map[string]string)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.handleAPI)
+	mux.HandleFunc("/files/", s.handleFile)
+	mux.HandleFunc("/response/", s.handleResponseURL)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// APIURL is passed to slack.OptionAPIURL.
+func (s *Server) APIURL() string {
+	return s.server.URL + "/api/"
+}
+
+// ResponseURL returns a response URL of a slash command or an interaction.
+func (s *Server) ResponseURL(name string) string {
+	return s.server.URL + "/response/" + url.PathEscape(name)
+}
+
+// AddUser registers a member of the channel.
+func (s *Server) AddUser(channelID string, user slack.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.members[channelID] = append(s.members[channelID], user.ID)
+	s.users[user.ID] = user
+}
+
+// AddFile registers the content of a file and returns its download URL.
+func (s *Server) AddFile(name string, body []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[name] = body
+
+	return s.server.URL + "/files/" + url.PathEscape(name)
+}
+
+// FailMethod makes the method return the error, such as "channel_not_found".
+func (s *Server) FailMethod(method string, slackError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errors[method] = slackError
+}
+
+// FailCall makes the method return the error only when the parameter has the value,
+// such as chat.postMessage to a channel.
+func (s *Server) FailCall(method string, param string, value string, slackError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.callErrors = append(s.callErrors, &callError{method: method, param: param, value: value, slackError: slackError})
+}
+
+// Calls returns the recorded calls of the methods, or all the calls if no method is specified.
+func (s *Server) Calls(methods ...string) []*Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []*Call
+	for _, c := range s.calls {
+		if len(methods) == 0 || containsString(methods, c.Method) {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+// Messages returns the messages posted to the channel or a response URL, in order.
+func (s *Server) Messages() ([]*slack.Msg, error) {
+	var msgs []*slack.Msg
+	for _, c := range s.Calls("chat.postMessage", "chat.postEphemeral", ResponseURL) {
+		msg, err := c.Message()
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+// Reset forgets the recorded calls.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+}
+
+func (s *Server) record(c *Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, c)
+}
+
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	call := &Call{Method: method}
+
+	// files.upload is sent as multipart with the parameters in the query, and the other methods as a form
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil {
+			writeJSON(w, map[string]interface{}{"ok": false, "error": "invalid_form_data"})
+			return
+		}
+		call.Values = r.URL.Query()
+		for k, v := range r.MultipartForm.Value {
+			call.Values[k] = append(call.Values[k], v...)
+		}
+		for _, fh := range r.MultipartForm.File["file"] {
+			f, err := fh.Open()
+			if err == nil {
+				call.Body, _ = ioutil.ReadAll(f)
+				f.Close()
+			}
+		}
+	} else {
+		r.ParseForm()
+		call.Values = r.PostForm
+	}
+	s.record(call)
+
+	s.mu.Lock()
+	slackError, failed := s.errors[method]
+	for _, e := range s.callErrors {
+		if e.method == method && call.Values.Get(e.param) == e.value {
+			slackError, failed = e.slackError, true
+		}
+	}
+	s.mu.Unlock()
+	if failed {
+		writeJSON(w, map[string]interface{}{"ok": false, "error": slackError})
+		return
+	}
+
+	switch method {
+	case "auth.test":
+		writeJSON(w, map[string]interface{}{"ok": true, "user_id": BotUserID, "user": "hitter"})
+	case "chat.postMessage":
+		writeJSON(w, map[string]interface{}{"ok": true, "channel": call.Values.Get("channel"), "ts": s.nextTimestamp()})
+	case "chat.postEphemeral":
+		writeJSON(w, map[string]interface{}{"ok": true, "message_ts": s.nextTimestamp()})
+	case "conversations.members":
+		s.handleMembers(w, call.Values)
+	case "conversations.open":
+		// The ID is made from the users, so that the same users get the same conversation
+		channel := map[string]interface{}{"id": "G" + strings.Replace(call.Values.Get("users"), ",", "", -1)}
+		writeJSON(w, map[string]interface{}{"ok": true, "channel": channel})
+	case "users.info":
+		s.handleUsersInfo(w, call.Values)
+	case "files.upload":
+		file := map[string]interface{}{"id": "F" + s.nextTimestamp(), "name": call.Values.Get("filename")}
+		writeJSON(w, map[string]interface{}{"ok": true, "file": file})
+	default:
+		writeJSON(w, map[string]interface{}{"ok": false, "error": "unknown_method"})
+	}
+}
+
+func (s *Server) handleMembers(w http.ResponseWriter, values url.Values) {
+	s.mu.Lock()
+	members, ok := s.members[values.Get("channel")]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, map[string]interface{}{"ok": false, "error": "channel_not_found"})
+		return
+	}
+
+	// The cursor is the offset of the next page
+	offset, _ := strconv.Atoi(values.Get("cursor"))
+	limit, _ := strconv.Atoi(values.Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	end := offset + limit
+	next := strconv.Itoa(end)
+	if end >= len(members) {
+		end = len(members)
+		next = ""
+	}
+	if offset > end {
+		offset = end
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"ok":                true,
+		"members":           members[offset:end],
+		"response_metadata": map[string]string{"next_cursor": next},
+	})
+}
+
+func (s *Server) handleUsersInfo(w http.ResponseWriter, values url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []slack.User
+	for _, id := range strings.Split(values.Get("users"), ",") {
+		u, ok := s.users[id]
+		if !ok {
+			writeJSON(w, map[string]interface{}{"ok": false, "error": "user_not_found"})
+			return
+		}
+		users = append(users, u)
+	}
+
+	writeJSON(w, map[string]interface{}{"ok": true, "users": users})
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/files/"))
+	s.record(&Call{Method: FileDownload, Name: name})
+
+	s.mu.Lock()
+	body, ok := s.files[name]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(body)
+}
+
+func (s *Server) handleResponseURL(w http.ResponseWriter, r *http.Request) {
+	name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/response/"))
+	body, _ := ioutil.ReadAll(r.Body)
+	s.record(&Call{Method: ResponseURL, Name: name, Body: body})
+
+	writeJSON(w, map[string]interface{}{"ok": true})
+}
+
+func (s *Server) nextTimestamp() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ts++
+
+	return fmt.Sprintf("1600000000.%06d", s.ts)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Methods returns the names of the recorded calls in order, which is handy for comparing.
+func (s *Server) Methods() []string {
+	var methods []string
+	for _, c := range s.Calls() {
+		methods = append(methods, c.Method)
+	}
+
+	return methods
+}
@@ -0,0 +1,67 @@
+package slacktest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestConversationsMembersPaging(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	for _, id := range []string{"U1", "U2", "U3"} {
+		s.AddUser("C1", slack.User{ID: id})
+	}
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.APIURL()))
+
+	var members []string
+	param := &slack.GetUsersInConversationParameters{ChannelID: "C1", Limit: 2}
+	for {
+		list, next, err := api.GetUsersInConversation(param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		members = append(members, list...)
+		if next == "" {
+			break
+		}
+		param.Cursor = next
+	}
+
+	if want := []string{"U1", "U2", "U3"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got %v, want %v", members, want)
+	}
+	if got := len(s.Calls("conversations.members")); got != 2 {
+		t.Errorf("got %d calls, want 2", got)
+	}
+}
+
+func TestFailMethod(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.FailMethod("chat.postMessage", "channel_not_found")
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.APIURL()))
+
+	_, _, err := api.PostMessage("C1", slack.MsgOptionText("hello", false))
+	if err == nil || err.Error() != "channel_not_found" {
+		t.Errorf("got %v", err)
+	}
+	if got := s.Calls()[0].Values.Get("text"); got != "hello" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestConversationsOpen(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.APIURL()))
+
+	ch, _, _, err := api.OpenConversation(&slack.OpenConversationParameters{Users: []string{"U1", "U2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.ID != "GU1U2" {
+		t.Errorf("got %q", ch.ID)
+	}
+}
@@ -0,0 +1,225 @@
+// Package storage provides the DynamoDB tables and the S3 bucket used by the bot and the redirect.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/guregu/dynamo"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// URLItem maps a shortened URL ID to the destination.
+type URLItem struct {
+	ID   string
+	URL  string
+	TTL  int64
+	Time time.Time
+}
+
+// MutexItem prevents the same slack event from being processed multiple times.
+type MutexItem struct {
+	ID string
+	// The outcome of the command, used to answer retried events
+	Status string
+	Result string
+	TTL    int64
+	Time   time.Time
+}
+
+// StateItem keeps the state of a command in a channel as JSON, such as the history of hit.
+type StateItem struct {
+	ID   string
+	Data string
+	// Incremented on every write, so that concurrent updates are detected
+	Version int64
+	TTL     int64
+	Time    time.Time
+}
+
+// ErrStateConflict is returned when the state was updated by another request after it was read.
+var ErrStateConflict = errors.New("the state was updated by another request")
+
+// Status of MutexItem
+const (
+	MutexRunning   = "running"
+	MutexSucceeded = "succeeded"
+	MutexFailed    = "failed"
+)
+
+// DynamoDB accesses the tables of hitter.
+type DynamoDB struct {
+	db *dynamo.DB
+}
+
+// NewDynamoDB connects to DynamoDB, or to DynamoDB Local if the endpoint is specified.
+func NewDynamoDB(sess *session.Session, endpoint string) *DynamoDB {
+	d := &DynamoDB{}
+	d.db = dynamo.New(sess)
+	if endpoint != "" {
+		d.db = dynamo.New(sess, aws.NewConfig().WithEndpoint(endpoint))
+	}
+
+	return d
+}
+
+// PutURLItem registers the destination of the shortened URL and returns its expiry as a unix time.
+func (d *DynamoDB) PutURLItem(ctx context.Context, tableName string, id string, urlStr string, days int) (int64, error) {
+	table := d.db.Table(tableName)
+
+	// put item
+	now := time.Now()
+	i := &URLItem{}
+	i.ID = id
+	i.URL = urlStr
+	i.Time = now
+	// TTL is per day.
+	i.TTL = now.AddDate(0, 0, days).Unix()
+
+	return i.TTL, table.Put(i).RunWithContext(ctx)
+}
+
+// GetURLItem returns the destination of the shortened URL.
+func (d *DynamoDB) GetURLItem(ctx context.Context, tableName string, id string) (*URLItem, error) {
+	table := d.db.Table(tableName)
+
+	// get item
+	var result URLItem
+	err := table.Get("ID", id).OneWithContext(ctx, &result)
+
+	return &result, err
+}
+
+// AcquireMutexItem registers the event ID and reports whether this request is the first one.
+// If the ID is already registered, the registered item is returned.
+func (d *DynamoDB) AcquireMutexItem(ctx context.Context, tableName string, id string) (*MutexItem, bool, error) {
+	table := d.db.Table(tableName)
+
+	// Register the slack event ID before execution.
+	// A single conditional write makes sure that only one request can run under the same ID.
+	now := time.Now()
+	i := &MutexItem{}
+	i.ID = id
+	i.Status = MutexRunning
+	i.Time = now
+	// Deleted after 24 hours.
+	i.TTL = now.Add(24 * time.Hour).Unix()
+
+	err := table.Put(i).If("attribute_not_exists(ID)").RunWithContext(ctx)
+	if err == nil {
+		return i, true, nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		logging.FromContext(ctx).Println("[ERROR] Failed to put the mutex item: ", err)
+		return nil, false, err
+	}
+
+	// If it exists, it returns the registered item.
+	item, err := d.GetMutexItem(ctx, tableName, id)
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to get the mutex item: ", err)
+		return nil, false, err
+	}
+
+	return item, false, nil
+}
+
+// RestartMutexItem marks the failed event as running again, and reports whether it succeeded.
+func (d *DynamoDB) RestartMutexItem(ctx context.Context, tableName string, id string) (bool, error) {
+	table := d.db.Table(tableName)
+
+	// Only the failed event can be run again, and only by one request
+	err := table.Update("ID", id).
+		Set("Status", MutexRunning).
+		Set("Result", "").
+		If("'Status' = ?", MutexFailed).
+		RunWithContext(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return false, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to restart the mutex item: ", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompleteMutexItem records the outcome of the event.
+func (d *DynamoDB) CompleteMutexItem(ctx context.Context, tableName string, id string, status string, result string) error {
+	table := d.db.Table(tableName)
+
+	// Record the final outcome of the command
+	err := table.Update("ID", id).
+		Set("Status", status).
+		Set("Result", result).
+		If("attribute_exists(ID)").
+		RunWithContext(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to update the mutex item: ", err)
+	}
+
+	return err
+}
+
+// GetMutexItem returns the registered event with a consistent read.
+func (d *DynamoDB) GetMutexItem(ctx context.Context, tableName string, id string) (*MutexItem, error) {
+	table := d.db.Table(tableName)
+
+	// get item
+	var result MutexItem
+	err := table.Get("ID", id).Consistent(true).OneWithContext(ctx, &result)
+
+	return &result, err
+}
+
+// GetStateItem returns the state with a consistent read, or an empty state of version 0 if there is none.
+func (d *DynamoDB) GetStateItem(ctx context.Context, tableName string, id string) (*StateItem, error) {
+	table := d.db.Table(tableName)
+
+	// get item
+	var result StateItem
+	err := table.Get("ID", id).Consistent(true).OneWithContext(ctx, &result)
+	if err == dynamo.ErrNotFound {
+		return &StateItem{ID: id}, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to get the state item: ", err)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PutStateItem writes the state only if it has not been updated since it was read, and increments its version.
+// The state is deleted after days without updates.
+func (d *DynamoDB) PutStateItem(ctx context.Context, tableName string, item *StateItem, days int) error {
+	table := d.db.Table(tableName)
+
+	now := time.Now()
+	i := *item
+	i.Version = item.Version + 1
+	i.Time = now
+	i.TTL = now.AddDate(0, 0, days).Unix()
+
+	put := table.Put(&i)
+	if item.Version == 0 {
+		put = put.If("attribute_not_exists(ID)")
+	} else {
+		put = put.If("Version = ?", item.Version)
+	}
+	err := put.RunWithContext(ctx)
+	if dynamo.IsCondCheckFailed(err) {
+		return ErrStateConflict
+	}
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to put the state item: ", err)
+		return err
+	}
+	*item = i
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// The tests run against DynamoDB Local, and are skipped unless DYNAMODB_ENDPOINT is set.
+//
+//	$ docker-compose up -d dynamodb-local
+//	$ DYNAMODB_ENDPOINT=http://localhost:8000 go test ./internal/storage/
+
+// The key of the tables, with the same name as the ID of the items
+type testKey struct {
+	ID string `dynamo:"ID,hash"`
+}
+
+// Create a table for the test, which is deleted at the end
+func setupTable(t *testing.T) (*DynamoDB, string) {
+	t.Helper()
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_ENDPOINT is not set")
+	}
+
+	// DynamoDB Local accepts any credentials
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("ap-northeast-1").
+		WithCredentials(credentials.NewStaticCredentials("hitter", "hitter", "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDynamoDB(sess, endpoint)
+
+	ctx := context.Background()
+	name := fmt.Sprintf("HitterTest%d", time.Now().UnixNano())
+	if err := d.db.CreateTable(name, testKey{}).OnDemand(true).RunWithContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := d.db.Table(name).DeleteTable().RunWithContext(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return d, name
+}
+
+func TestMutexItem(t *testing.T) {
+	d, table := setupTable(t)
+	ctx := context.Background()
+
+	// The first request acquires the mutex
+	item, first, err := d.AcquireMutexItem(ctx, table, "Ev0001")
+	if err != nil || !first || item.Status != MutexRunning {
+		t.Fatalf("first acquire = %+v, %v, %v", item, first, err)
+	}
+
+	// The retried request gets the registered item
+	item, first, err = d.AcquireMutexItem(ctx, table, "Ev0001")
+	if err != nil || first || item.Status != MutexRunning {
+		t.Fatalf("second acquire = %+v, %v, %v", item, first, err)
+	}
+
+	// Only the failed event can be restarted
+	if ok, err := d.RestartMutexItem(ctx, table, "Ev0001"); err != nil || ok {
+		t.Fatalf("restart of running event = %v, %v", ok, err)
+	}
+	if err := d.CompleteMutexItem(ctx, table, "Ev0001", MutexFailed, "channel_not_found"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := d.RestartMutexItem(ctx, table, "Ev0001"); err != nil || !ok {
+		t.Fatalf("restart of failed event = %v, %v", ok, err)
+	}
+	item, err = d.GetMutexItem(ctx, table, "Ev0001")
+	if err != nil || item.Status != MutexRunning || item.Result != "" {
+		t.Fatalf("restarted item = %+v, %v", item, err)
+	}
+
+	// Another request cannot restart it again
+	if ok, err := d.RestartMutexItem(ctx, table, "Ev0001"); err != nil || ok {
+		t.Fatalf("second restart = %v, %v", ok, err)
+	}
+
+	// The outcome is kept for the retries
+	if err := d.CompleteMutexItem(ctx, table, "Ev0001", MutexSucceeded, "done"); err != nil {
+		t.Fatal(err)
+	}
+	item, first, err = d.AcquireMutexItem(ctx, table, "Ev0001")
+	if err != nil || first || item.Status != MutexSucceeded || item.Result != "done" {
+		t.Fatalf("acquire of completed event = %+v, %v, %v", item, first, err)
+	}
+
+	// An event which was never acquired cannot be completed
+	if err := d.CompleteMutexItem(ctx, table, "Ev0002", MutexSucceeded, ""); err == nil {
+		t.Error("complete of unknown event succeeded")
+	}
+}
+
+func TestStateItem(t *testing.T) {
+	d, table := setupTable(t)
+	ctx := context.Background()
+
+	item, err := d.GetStateItem(ctx, table, "hit#C0123")
+	if err != nil || item.Version != 0 || item.Data != "" {
+		t.Fatalf("empty state = %+v, %v", item, err)
+	}
+
+	// Two requests read the same empty state
+	stale := *item
+	item.Data = `{"rounds":[]}`
+	if err := d.PutStateItem(ctx, table, item, 1); err != nil {
+		t.Fatal(err)
+	}
+	if item.Version != 1 {
+		t.Errorf("version = %d, want 1", item.Version)
+	}
+	stale.Data = `{"rounds":[{"users":["U0001"]}]}`
+	if err := d.PutStateItem(ctx, table, &stale, 1); err != ErrStateConflict {
+		t.Errorf("put of stale empty state = %v, want ErrStateConflict", err)
+	}
+
+	// Two requests read the same existing state
+	item, err = d.GetStateItem(ctx, table, "hit#C0123")
+	if err != nil || item.Version != 1 || item.Data != `{"rounds":[]}` {
+		t.Fatalf("state = %+v, %v", item, err)
+	}
+	stale = *item
+	if err := d.PutStateItem(ctx, table, item, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.PutStateItem(ctx, table, &stale, 1); err != ErrStateConflict {
+		t.Errorf("put of stale state = %v, want ErrStateConflict", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("stale version was changed to %d", stale.Version)
+	}
+
+	item, err = d.GetStateItem(ctx, table, "hit#C0123")
+	if err != nil || item.Version != 2 {
+		t.Fatalf("state = %+v, %v", item, err)
+	}
+}
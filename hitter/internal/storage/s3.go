@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
+)
+
+// S3Item is the uploaded object and its pre-signed URL.
+type S3Item struct {
+	Bucket       string
+	Key          string
+	ObjectExpiry string
+	PreSignedURL string
+	URLExpiry    string
+}
+
+// S3 accesses the bucket of hitter.
+type S3 struct {
+	client *s3.S3
+}
+
+// NewS3 creates the S3 client.
+func NewS3(sess *session.Session) *S3 {
+	c := &S3{}
+	c.client = s3.New(sess)
+
+	return c
+}
+
+// UploadAndPreSignedURL uploads the file and returns the object information with a pre-signed URL valid for min minutes.
+func (c *S3) UploadAndPreSignedURL(ctx context.Context, bucket string, key string, body []byte, min int) (*S3Item, error) {
+	result := &S3Item{}
+
+	// Upload file to S3
+	objectExpiry, err := c.Upload(ctx, bucket, key, body)
+	if err != nil {
+		return result, err
+	}
+
+	// Create pre-signed URL
+	preURL, urlExpiry, err := c.CreatePreSignedURL(ctx, bucket, key, min)
+	if err != nil {
+		return result, err
+	}
+
+	// Set the result
+	result.Bucket = bucket
+	result.Key = key
+	result.ObjectExpiry = objectExpiry
+	result.PreSignedURL = preURL
+	result.URLExpiry = urlExpiry
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("s3Item: %+v\n", result)
+
+	return result, nil
+}
+
+// Upload puts the object and returns its expiry date for display.
+func (c *S3) Upload(ctx context.Context, bucket string, key string, body []byte) (string, error) {
+	// Set the information of the object to be put
+	input := &s3.PutObjectInput{
+		Body:   aws.ReadSeekCloser(bytes.NewReader(body)),
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("input: %+v\n", input)
+
+	// Uploading Objects to S3
+	result, err := c.client.PutObjectWithContext(ctx, input)
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to upload object to S3: ", err)
+		return "", err
+	}
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("result: %+v\n", result)
+
+	// Extract the expiry date
+	// ex.) expiry-date="Tue, 15 Sep 2020 00:00:00 GMT", rule-id="YzhmY2RkZTUtYmM0OS00NTE5LWE3NjctODNjM2QwMTU2MDFm"
+	text := *result.Expiration
+	i := strings.Index(text, `"`)
+	text = text[i+1:]
+	i = strings.Index(text, `"`)
+	text = text[:i]
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("expiry-date: %+v\n", text)
+
+	dispDate, _ := datetime.DisplayDateString(text, "")
+
+	return dispDate, nil
+}
+
+// CreatePreSignedURL returns a pre-signed URL to get the object and its expiry date for display.
+func (c *S3) CreatePreSignedURL(ctx context.Context, bucket string, key string, min int) (string, string, error) {
+	// Set the information of the object to be get
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	// The default lifetime is 15 minutes.
+	if min <= 0 {
+		min = 15
+	}
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("input: %+v\n", input)
+	logging.FromContext(ctx).Debugf("min: %+v\n", min)
+
+	// Requesting a get object
+	req, _ := c.client.GetObjectRequest(input)
+	if req.Error != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to get object request: ", req.Error)
+		return "", "", req.Error
+	}
+
+	// Get pre-signed URL
+	urlStr, err := req.Presign(time.Duration(min) * time.Minute)
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to pre-sign request: ", err)
+		return "", "", err
+	}
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("urlStr: %+v\n", urlStr)
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		logging.FromContext(ctx).Println("[ERROR] Failed to parse pre-signed URL: ", err)
+		return "", "", err
+	}
+
+	// Output debug log
+	logging.FromContext(ctx).Debugf("RawQuery: %s\n", u.RawQuery)
+	logging.FromContext(ctx).Debugf("RawQuery: %s\n", u.Query()["X-Amz-Date"][0])
+	logging.FromContext(ctx).Debugf("RawQuery: %s\n", u.Query()["X-Amz-Expires"][0])
+
+	// Convert the format 20200918T142930Z to 20200918142930
+	tt := u.Query()["X-Amz-Date"][0]
+	tt = strings.Replace(tt, "T", "", -1)
+	tt = strings.Replace(tt, "Z", "", -1)
+
+	dispDate, _ := datetime.DisplayDateString(tt, u.Query()["X-Amz-Expires"][0])
+
+	return urlStr, dispDate, nil
+}
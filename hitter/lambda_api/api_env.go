@@ -1,45 +1,48 @@
 package main
 
 import (
-	"log"
+	"context"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/uchimanajet7/hitter/hitter/internal/config"
+	"github.com/uchimanajet7/hitter/hitter/internal/lazy"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
 )
 
 // Keep information obtained from environment variables in global variables.
 var envconf *envConfig
 
-// Be careful!
-// The required specification is different from the one used in the slack bot's Lambda mission
+// The log with the fields of the request, such as the Lambda request ID
+func logger(ctx context.Context) *logging.Logger {
+	return logging.FromContext(ctx)
+}
+
+// The redirect only needs the settings shared with the slack bot's Lambda function
 type envConfig struct {
-	SlackOAuthAccessToken  string `envconfig:"SLACK_OAUTH_ACCESS_TOKEN"`
-	SlackVerificationToken string `envconfig:"SLACK_VERIFICATION_TOKEN"`
-	MutexTableName         string `envconfig:"MUTEX_TABLE_NAME"`
-	URLTableName           string `envconfig:"URL_TABLE_NAME" required:"true"`
-	S3BucketName           string `envconfig:"S3_BUCKET_NAME"`
-	APIBaseURL             string `envconfig:"API_BASE_URL"`
-	SlackChannelID         string `envconfig:"SLACK_CHANNEL_ID"`
-	DebugLog               bool   `envconfig:"DEBUG_LOG"`
+	config.Common
 }
 
 func loadEnvConfig() (*envConfig, error) {
 	// Load environment variables
 	env := &envConfig{}
 
-	err := envconfig.Process("", env)
+	err := config.Load(env)
 	if err != nil {
-		log.Println("[ERROR] Failed to load environment variables: ", err)
 		return env, err
 	}
 
 	// Keep information obtained from environment variables in global variables.
 	envconf = env
 
-	// Debug log output settings
-	debug = debugT(env.DebugLog)
+	return env, err
+}
+
+// The environment does not change while the Lambda container is warm
+var sharedEnv lazy.Value
 
-	// Output debug log
-	debug.Printf("env: %+v\n", env)
+func getEnvConfig() (*envConfig, error) {
+	v, err := sharedEnv.Get(func() (interface{}, error) {
+		return loadEnvConfig()
+	})
 
-	return env, err
+	return v.(*envConfig), err
 }
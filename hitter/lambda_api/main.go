@@ -3,40 +3,54 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/uchimanajet7/hitter/hitter/internal/datetime"
+	"github.com/uchimanajet7/hitter/hitter/internal/httpproxy"
+	"github.com/uchimanajet7/hitter/hitter/internal/logging"
 )
 
 // Returning the error will result in "message": "Internal server error" with 502 Bad Gateway, so do not return the error if you want a custom display.
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// Every line of the log carries the request ID of the invocation
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		ctx = logging.NewContext(ctx, logging.Fields{"request_id": lc.AwsRequestID})
+	}
+
 	// Load information from environment variables and make it available on a global basis
-	env, err := loadEnvConfig()
+	env, err := getEnvConfig()
 	if err != nil {
 		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
 	}
 
 	// Output debug log
-	debug.Printf("request: %+v\n", request)
+	logger(ctx).Debugf("request: %+v\n", request)
 
 	// Parse redirect ID from path
 	id := request.PathParameters["proxy"]
-	log.Printf("id: %+v\n", id)
+	ctx = logging.NewContext(ctx, logging.Fields{"redirect_id": id})
+	logger(ctx).Printf("[REDIRECT] id: %+v\n", id)
 
 	// Initialize the aws client
-	aws := newAwsClient()
+	aws, err := getAwsClient()
+	if err != nil {
+		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+	}
 
 	result := `{"result": "ok"}`
 
 	// Acquiring URL information of the redirected destination from Dynamo DB
-	urlItem, err := aws.getURLItem(env.URLTableName, id)
+	urlItem, err := aws.getURLItem(ctx, env.URLTableName, id)
 	if err != nil {
 		result = `{"Error": "No redirect found for id [` + id + `]"}`
 		return events.APIGatewayProxyResponse{Body: result, StatusCode: 400}, nil
 	}
-	log.Printf("urlItem: %+v\n", urlItem)
+	logger(ctx).Printf("[REDIRECT] urlItem: %+v\n", urlItem)
 
 	// If there is no redirection destination, there is an error
 	location := strings.TrimSpace(urlItem.URL)
@@ -47,7 +61,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 
 	// The URL itself has an expiration date, so display it
 	urlExpiry := strings.TrimSpace(strconv.FormatInt(urlItem.TTL, 10))
-	dateStr, err := getHTTPDateString(urlExpiry)
+	dateStr, err := datetime.HTTPDateString(urlExpiry)
 	if err == nil {
 		urlExpiry = dateStr
 	}
@@ -62,6 +76,27 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 	return events.APIGatewayProxyResponse{Headers: headers, Body: result, StatusCode: 302}, nil
 }
 
+// The HTTP server mode runs the same handler as API Gateway without AWS Lambda,
+// so the redirect can run on-prem and the whole flow can be tested locally.
+func runHTTPServer() error {
+	// Load information from environment variables and make it available on a global basis
+	env, err := getEnvConfig()
+	if err != nil {
+		return err
+	}
+
+	return httpproxy.ListenAndServe(env.ListenAddr, handleRequest)
+}
+
 func main() {
-	lambda.Start(handleRequest)
+	switch os.Getenv("RUN_MODE") {
+	case "http":
+		// Run as a standalone HTTP server
+		err := runHTTPServer()
+		if err != nil {
+			log.Fatalln("[ERROR] The HTTP server stopped: ", err)
+		}
+	default:
+		lambda.Start(handleRequest)
+	}
 }